@@ -2,10 +2,14 @@ package actions
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/eris-ltd/eris-cli/config"
 	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
 
 	log "github.com/eris-ltd/eris-logger"
 	dir "github.com/eris-ltd/common/go/common"
@@ -24,11 +28,26 @@ func LoadActionDefinition(actionName string) (*def.Action, []string, error) {
 		return action, actionVars, err
 	}
 
-	err = marshalActionDefinition(actionConf, action)
+	name := strings.TrimSuffix(filepath.Base(actionConf.ConfigFileUsed()), filepath.Ext(actionConf.ConfigFileUsed()))
+	levels, err := loaders.ExtendedDefinitions(dir.ActionsPath, name, actionConf)
 	if err != nil {
 		return action, actionVars, err
 	}
 
+	// Steps of the base actions come first.
+	for i, level := range levels {
+		levelAction := def.BlankAction()
+		if err = marshalActionDefinition(level, levelAction); err != nil {
+			return action, actionVars, err
+		}
+		if i != len(levels)-1 {
+			levelAction.Name = ""
+		}
+		if err = util.DeepMerge(action, levelAction); err != nil {
+			return action, actionVars, err
+		}
+	}
+
 	if len(dropped) != 0 {
 		fixSteps(action, dropped)
 	}
@@ -80,7 +99,9 @@ func readActionDefinition(actionName []string, dropped map[string]string, varNum
 
 	var actionConf = viper.New()
 
-	actionConf.AddConfigPath(dir.ActionsPath)
+	for _, path := range config.DefinitionPaths(dir.ActionsPath) {
+		actionConf.AddConfigPath(path)
+	}
 	actionConf.SetConfigName(strings.Join(actionName, "_"))
 	err := actionConf.ReadInConfig()
 
@@ -30,39 +30,35 @@ func NewAction(do *definitions.Do) error {
 	return nil
 }
 
+// ImportAction fetches the action definition file from a location
+// (see loaders.Resolvers), validates it and writes it into ActionsPath.
+// The IPFS service is started for IPFS locations.
+//
+//  do.Name - name of the action to import (defaults to do.Operations.Args)
+//  do.Path - location of the definition file (required)
+//
 func ImportAction(do *definitions.Do) error {
 	if do.Name == "" {
 		do.Name = strings.Join(do.Operations.Args, "_")
 	}
-	fileName := filepath.Join(ActionsPath, strings.Join(do.Operations.Args, " "))
-	if filepath.Ext(fileName) == "" {
-		fileName = fileName + ".toml"
-	}
-
-	s := strings.Split(do.Path, ":")
-	if s[0] == "ipfs" {
 
-		var err error
+	if loaders.LocationScheme(do.Path) == "ipfs" {
 		ipfsService, err := loaders.LoadServiceDefinition("ipfs")
 		if err != nil {
 			return err
 		}
 
 		ipfsService.Operations.ContainerType = definitions.TypeService
-		err = perform.DockerRunService(ipfsService.Service, ipfsService.Operations)
-		if err != nil {
+		if err := perform.DockerRunService(ipfsService.Service, ipfsService.Operations); err != nil {
 			return err
 		}
-
-		return ipfs.GetFromIPFS(s[1], fileName, "")
 	}
 
-	if strings.Contains(s[0], "github") {
-		log.Warn("https://twitter.com/ryaneshea/status/595957712040628224")
-		return nil
+	if _, err := loaders.ImportDefinition("actions", do.Name, do.Path); err != nil {
+		return err
 	}
 
-	log.Warn("Failed to get that file. Sorry")
+	do.Result = "success"
 	return nil
 }
 
@@ -9,7 +9,9 @@ import (
 
 	"github.com/eris-ltd/eris-cli/chains"
 	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
 	"github.com/eris-ltd/eris-cli/services"
+	"github.com/eris-ltd/eris-cli/util"
 
 	log "github.com/eris-ltd/eris-logger"
 )
@@ -29,6 +31,9 @@ func Do(do *definitions.Do) error {
 
 	resolveServices(do)
 	resolveChain(do)
+	if err := loaders.Interpolate(do.Action, do.ChainName); err != nil {
+		return fmt.Errorf("Cannot load the %s action definition: %v", do.Action.Name, err)
+	}
 	fixChain(do.Action, do.ChainName)
 
 	if err := StartServicesAndChains(do); err != nil {
@@ -42,31 +47,38 @@ func Do(do *definitions.Do) error {
 	return nil
 }
 
+// StartServicesAndChains starts the chain the action depends on with
+// chains.StartChain (with the do.Env, do.Links, and other start options)
+// and then the services (and their dependencies) in the dependency order.
+// The action chain overwrites the `chain` field of the service definitions
+// (see services.NewDependencyGraph).
 func StartServicesAndChains(do *definitions.Do) error {
-	// start the services and chains
-	doSrvs := definitions.NowDo()
-	if do.Action.Dependencies == nil || len(do.Action.Dependencies.Services) == 0 {
-		log.Debug("No services to start")
+	if do.Action.Chain == "" {
+		log.Debug("No chain to start")
 	} else {
-		doSrvs.Operations.Args = do.Action.Dependencies.Services
-		log.WithField("args", doSrvs.Operations.Args).Debug("Starting services")
-		if err := services.StartService(doSrvs); err != nil {
+		log.WithField("=>", do.Action.Chain).Debug("Starting chain")
+		doChain := *do
+		doChain.Name = do.Action.Chain
+		if err := chains.StartChain(&doChain); err != nil {
 			return err
 		}
 	}
 
-	doChns := definitions.NowDo()
-	doChns.Name = do.Action.Chain
-	if doChns.Name == "" {
-		log.Debug("No chain to start")
-	} else {
-		log.WithField("=>", doChns.Name).Debug("Starting chain")
-		if err := chains.StartChain(do); err != nil {
-			return err
-		}
+	if do.Action.Dependencies == nil || len(do.Action.Dependencies.Services) == 0 {
+		log.Debug("No services to start")
+		return nil
 	}
 
-	return nil
+	log.WithField("args", do.Action.Dependencies.Services).Debug("Starting services")
+	graph, err := services.BuildDependencyGraph(do.Action.Chain, do.Action.Dependencies.Services...)
+	if err != nil {
+		return err
+	}
+	levels, err := graph.Levels()
+	if err != nil {
+		return err
+	}
+	return services.StartGroup(levels)
 }
 
 func PerformCommand(action *definitions.Action, actionVars []string, quiet bool) error {
@@ -123,6 +135,11 @@ func PerformCommand(action *definitions.Action, actionVars []string, quiet bool)
 }
 
 func resolveChain(do *definitions.Do) {
+	if do.ChainName == "" && usesChainVariable(do.Action) {
+		// fall back to the checked out (or project-local) chain
+		do.ChainName, _ = util.GetHead()
+	}
+
 	if do.ChainName == "" { // do.ChainName populated via CLI flag
 		do.Action.Chain = do.ChainName
 	}
@@ -132,6 +149,20 @@ func resolveChain(do *definitions.Do) {
 	}
 }
 
+// usesChainVariable returns true if the action chain or any of
+// its steps refer to the `$chain` or `${chain}` variables.
+func usesChainVariable(action *definitions.Action) bool {
+	if action.Chain == "$chain" || strings.Contains(action.Chain, "${chain") {
+		return true
+	}
+	for _, step := range action.Steps {
+		if strings.Contains(step, "$chain") || strings.Contains(step, "${chain") {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveServices(do *definitions.Do) {
 	if do.Action.Dependencies != nil {
 		do.Action.Dependencies.Services = append(do.Action.Dependencies.Services, do.ServicesSlice...)
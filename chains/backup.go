@@ -0,0 +1,275 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/data"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+	"github.com/eris-ltd/eris-cli/version"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// Backup archive layout.
+const (
+	backupManifest   = "manifest.json"
+	backupDefinition = "chain" // + the definition file extension
+	backupData       = "data"
+)
+
+// BackupManifest describes the contents of a chain backup archive.
+type BackupManifest struct {
+	Chain       string            `json:"chain"`
+	ChainID     string            `json:"chain_id"`
+	Created     time.Time         `json:"created"`
+	ErisVersion string            `json:"eris_version"`
+	Definition  string            `json:"definition"`
+	Images      map[string]string `json:"images"`
+}
+
+// BackupChain writes the chain data container contents, the chain
+// definition file and a manifest of image versions into a gzipped tarball.
+// A running chain is stopped for the duration of the backup to get
+// a consistent copy of the data and restarted afterwards. BackupChain
+// returns Docker or file system errors.
+//
+//  do.Name    - name of the chain to back up (required)
+//  do.Path    - backup file name (defaults to NAME_<timestamp>.tar.gz)
+//  do.Timeout - time to wait for the chain container to stop
+//
+func BackupChain(do *definitions.Do) error {
+	chain, err := loaders.LoadChainDefinition(do.Name)
+	if err != nil {
+		return err
+	}
+
+	if !util.IsData(chain.Name) {
+		return fmt.Errorf("Chain %s has no data container to back up", chain.Name)
+	}
+
+	defFile := util.GetFileByNameAndType("chains", chain.Name)
+	if defFile == "" {
+		return fmt.Errorf("I cannot find the %s chain definition file. Please check the chain name you sent me", chain.Name)
+	}
+
+	if do.Path == "" {
+		do.Path = fmt.Sprintf("%s_%s.tar.gz", chain.Name, time.Now().Format("20060102150405"))
+	}
+
+	manifest := &BackupManifest{
+		Chain:       chain.Name,
+		ChainID:     chain.ChainID,
+		Created:     time.Now().UTC(),
+		ErisVersion: version.VERSION,
+		Definition:  backupDefinition + filepath.Ext(defFile),
+		Images: map[string]string{
+			definitions.TypeChain: containerImage(chain.Operations.SrvContainerName, chain.Service.Image),
+			definitions.TypeData:  containerImage(chain.Operations.DataContainerName, ""),
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "eris_backup_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Stop the chain so that the data doesn't change while it's copied.
+	if err := whileStopped(do, chain, func() error {
+		return exportChainData(chain.Name, filepath.Join(dir, backupData))
+	}); err != nil {
+		return err
+	}
+
+	if err := Copy(defFile, filepath.Join(dir, manifest.Definition)); err != nil {
+		return err
+	}
+
+	if err := writeBackupManifest(filepath.Join(dir, backupManifest), manifest); err != nil {
+		return err
+	}
+
+	log.WithField("file", do.Path).Warn("Writing chain backup")
+	if _, err := util.PackTarball(dir, do.Path); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// RestoreChain recreates the chain data container and the chain
+// definition file from a backup made by BackupChain. The chain is
+// restored under its original name unless do.NewName is given.
+// RestoreChain refuses to overwrite an existing chain.
+//
+//  do.Path    - backup file name (required)
+//  do.NewName - name of the restored chain (optional)
+//
+func RestoreChain(do *definitions.Do) error {
+	dir, err := ioutil.TempDir("", "eris_restore_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	log.WithField("file", do.Path).Info("Unpacking chain backup")
+	if err := util.UnpackTarball(do.Path, dir); err != nil {
+		return err
+	}
+
+	manifest, err := readBackupManifest(filepath.Join(dir, backupManifest))
+	if err != nil {
+		return fmt.Errorf("The file %s is not a chain backup: %v", do.Path, err)
+	}
+
+	name := manifest.Chain
+	if do.NewName != "" {
+		name = do.NewName
+	}
+
+	if util.IsData(name) || util.IsKnownChain(name) {
+		return fmt.Errorf("Chain %s already exists. Please remove it with [eris chains rm -xf --file %[1]s] or restore it under a different name", name)
+	}
+
+	log.WithFields(log.Fields{
+		"=>":       name,
+		"chain id": manifest.ChainID,
+		"created":  manifest.Created,
+	}).Warn("Restoring chain")
+
+	// The definition file is restored as is, without resolving
+	// its variables, extends, and overlays (see RenameChain).
+	defFile := filepath.Join(ChainsPath, name+filepath.Ext(manifest.Definition))
+	if name != manifest.Chain {
+		log.WithField("=>", name).Debug("Renaming chain definition file")
+		definition, err := config.LoadViperConfig(dir, backupDefinition)
+		if err != nil {
+			return err
+		}
+		if err := config.RenameDefinitionFile(definition, name, defFile); err != nil {
+			return err
+		}
+	} else if err := Copy(filepath.Join(dir, manifest.Definition), defFile); err != nil {
+		return err
+	}
+
+	chain, err := loaders.LoadChainDefinition(name)
+	if err != nil {
+		return err
+	}
+
+	if image := manifest.Images[definitions.TypeChain]; image != "" && image != chain.Service.Image {
+		log.WithFields(log.Fields{
+			"backup":  image,
+			"current": chain.Service.Image,
+		}).Warn("The chain image differs from the one in the backup")
+	}
+
+	if err := importChainData(name, filepath.Join(dir, backupData)); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// whileStopped stops the chain container if it's running, calls fn,
+// and then restarts the chain with StartChain (with the do.Env, do.Links,
+// and other start options).
+func whileStopped(do *definitions.Do, chain *definitions.Chain, fn func() error) (err error) {
+	if !util.IsChain(chain.Name, true) {
+		return fn()
+	}
+
+	log.WithField("=>", chain.Name).Warn("Stopping chain to copy its data")
+	if err := perform.DockerStop(chain.Service, chain.Operations, do.Timeout); err != nil {
+		return err
+	}
+	defer func() {
+		log.WithField("=>", chain.Name).Warn("Restarting chain")
+		restart := *do
+		restart.Name = chain.Name
+		if errStart := StartChain(&restart); errStart != nil && err == nil {
+			err = errStart
+		}
+	}()
+
+	return fn()
+}
+
+// exportChainData copies the contents of the chain
+// data container to the dir directory on the host.
+func exportChainData(name, dir string) error {
+	log.WithField("=>", name).Info("Exporting chain data")
+	do := definitions.NowDo()
+	do.Name = name
+	do.Source = ErisContainerRoot
+	do.Destination = dir
+	return data.ExportData(do)
+}
+
+// importChainData creates the chain data container (if it doesn't
+// exist) and copies the contents of the dir directory into it.
+func importChainData(name, dir string) error {
+	log.WithField("=>", name).Info("Importing chain data")
+	do := definitions.NowDo()
+	do.Name = name
+	do.Source = dir
+	do.Destination = ErisContainerRoot
+	return data.ImportData(do)
+}
+
+// containerImage returns the image the container was created from
+// or the fallback value if the container doesn't exist.
+func containerImage(name, fallback string) string {
+	container, err := util.DockerClient.InspectContainer(name)
+	if err != nil {
+		return fallback
+	}
+	return container.Config.Image
+}
+
+func writeBackupManifest(fileName string, manifest *BackupManifest) error {
+	mar, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, append(mar, '\n'), 0644)
+}
+
+func readBackupManifest(fileName string) (*BackupManifest, error) {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := new(BackupManifest)
+	if err := json.Unmarshal(contents, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Chain == "" || strings.ContainsAny(manifest.Chain, `/\`) || strings.Contains(manifest.Chain, "..") {
+		return nil, fmt.Errorf("invalid chain name %q in the manifest", manifest.Chain)
+	}
+
+	// The definition file name is joined with the unpacked archive
+	// directory, so only the names BackupChain writes are accepted.
+	switch manifest.Definition {
+	case backupDefinition + ".toml", backupDefinition + ".json", backupDefinition + ".yaml":
+	default:
+		return nil, fmt.Errorf("invalid definition file name %q in the manifest", manifest.Definition)
+	}
+	return manifest, nil
+}
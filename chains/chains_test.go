@@ -3,13 +3,16 @@ package chains
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/data"
 	def "github.com/eris-ltd/eris-cli/definitions"
 	ini "github.com/eris-ltd/eris-cli/initialize"
 	"github.com/eris-ltd/eris-cli/loaders"
@@ -192,6 +195,46 @@ func TestCatChainContainerGenesis(t *testing.T) {
 	}
 }
 
+func TestConfigChain(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	buf := new(bytes.Buffer)
+	config.GlobalConfig.Writer = buf
+
+	do := def.NowDo()
+	do.ConfigFile = filepath.Join(common.ChainsPath, "default", "config.toml")
+	do.Name = chainName
+	do.Operations.PublishAllPorts = true
+	if err := NewChain(do); err != nil {
+		t.Fatalf("expected a new chain to be created, got %v", err)
+	}
+
+	do = def.NowDo()
+	do.Name = chainName
+	do.Type = "set"
+	do.Operations.Args = []string{"fast_sync=no"}
+	if err := ConfigChain(do); err == nil {
+		t.Fatalf("expected a non-boolean fast_sync value to fail")
+	}
+
+	do.Operations.Args = []string{"moniker=configured", "fast_sync=false"}
+	do.Yes = true
+	if err := ConfigChain(do); err != nil {
+		t.Fatalf("expected config values to be set, got %v", err)
+	}
+
+	do = def.NowDo()
+	do.Name = chainName
+	do.Type = "get"
+	do.Operations.Args = []string{"moniker"}
+	if err := ConfigChain(do); err != nil {
+		t.Fatalf("expected config value to be read, got %v", err)
+	}
+	if buf.String() != "configured\n" {
+		t.Fatalf("expected the changed moniker, got %q", buf.String())
+	}
+}
+
 func TestChainsNewDirGenesis(t *testing.T) {
 	defer tests.RemoveAllContainers()
 
@@ -394,6 +437,592 @@ func TestRmChain(t *testing.T) {
 	}
 }
 
+func TestBackupRestoreChain(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	const restored = "restored-chain"
+
+	create(t, chainName)
+
+	backup := filepath.Join(erisDir, "backup.tar.gz")
+	defer os.Remove(backup)
+
+	do := def.NowDo()
+	do.Name = chainName
+	do.Path = backup
+	if err := BackupChain(do); err != nil {
+		t.Fatalf("expected chain to be backed up, got %v", err)
+	}
+	if !util.Running(def.TypeChain, chainName) {
+		t.Fatalf("expecting chain to be running after the backup")
+	}
+
+	do = def.NowDo()
+	do.Path = backup
+	if err := RestoreChain(do); err == nil {
+		t.Fatalf("expected restore over an existing chain to fail")
+	}
+
+	do = def.NowDo()
+	do.Path = backup
+	do.NewName = restored
+	if err := RestoreChain(do); err != nil {
+		t.Fatalf("expected chain to be restored, got %v", err)
+	}
+	defer os.Remove(filepath.Join(common.ChainsPath, restored+".toml"))
+
+	if !util.Exists(def.TypeData, restored) {
+		t.Fatalf("expecting restored data container exists")
+	}
+	if !util.IsKnownChain(restored) {
+		t.Fatalf("expecting restored chain definition file exists")
+	}
+
+	do = def.NowDo()
+	do.Name = restored
+	do.Operations.Args = []string{"ls", path.Join(common.ErisContainerRoot, "chains", chainName)}
+	buf, err := data.ExecData(do)
+	if err != nil {
+		t.Fatalf("expected to list restored data, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "config.toml") {
+		t.Fatalf("expected restored data to contain config.toml, got %q", buf.String())
+	}
+}
+
+func TestReadBackupManifest(t *testing.T) {
+	manifest := filepath.Join(erisDir, backupManifest)
+	defer os.Remove(manifest)
+
+	for _, entry := range []struct {
+		chain, definition string
+		valid             bool
+	}{
+		{"test", "chain.toml", true},
+		{"test", "chain.yaml", true},
+		{"test", "chain.yml", false},
+		{"test", "chain/../../../x", false},
+		{"test", "../chain.toml", false},
+		{"../test", "chain.toml", false},
+		{"", "chain.toml", false},
+	} {
+		if err := writeBackupManifest(manifest, &BackupManifest{Chain: entry.chain, Definition: entry.definition}); err != nil {
+			t.Fatalf("cannot write the manifest: %v", err)
+		}
+		if _, err := readBackupManifest(manifest); (err == nil) != entry.valid {
+			t.Fatalf("expected manifest %q, %q to be valid = %v, got %v", entry.chain, entry.definition, entry.valid, err)
+		}
+	}
+}
+
+func TestCloneChain(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	const clone = "cloned-chain"
+
+	create(t, chainName)
+
+	do := def.NowDo()
+	do.Name = chainName
+	do.NewName = clone
+	do.ChainID = clone
+	if err := CloneChain(do); err != nil {
+		t.Fatalf("expected chain to be cloned, got %v", err)
+	}
+	defer os.Remove(filepath.Join(common.ChainsPath, clone+".toml"))
+
+	if !util.Running(def.TypeChain, chainName) {
+		t.Fatalf("expecting source chain to be running after the clone")
+	}
+	if !util.Exists(def.TypeData, clone) {
+		t.Fatalf("expecting cloned data container exists")
+	}
+
+	chain, err := loaders.LoadChainDefinition(clone)
+	if err != nil {
+		t.Fatalf("expected cloned chain definition to load, got %v", err)
+	}
+	if chain.ChainID != clone {
+		t.Fatalf("expected cloned chain ID %q, got %q", clone, chain.ChainID)
+	}
+
+	do = def.NowDo()
+	do.Name = clone
+	do.Operations.Args = []string{"cat", path.Join(common.ErisContainerRoot, "chains", clone, "genesis.json")}
+	buf, err := data.ExecData(do)
+	if err != nil {
+		t.Fatalf("expected to read cloned genesis file, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `"chain_id": "`+clone+`"`) {
+		t.Fatalf("expected cloned genesis file to have the new chain ID, got %q", buf.String())
+	}
+}
+
+func TestRewriteChainIdentity(t *testing.T) {
+	dir := filepath.Join(erisDir, "rewrite", "chains")
+	defer os.RemoveAll(filepath.Dir(dir))
+
+	if err := os.MkdirAll(filepath.Join(dir, "old", "data"), 0755); err != nil {
+		t.Fatalf("can't create the chain directory: %v", err)
+	}
+	files := map[string]string{
+		"genesis.json": `{"chain_id": "old", "accounts": [{"amount": 9999999999999999}]}`,
+		"config.toml":  "moniker = \"old\"\nchain_id = \"old\"\n",
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, "old", name), []byte(contents), 0644); err != nil {
+			t.Fatalf("can't write %s: %v", name, err)
+		}
+	}
+
+	if err := rewriteChainIdentity(dir, "old", "new", false); err != nil {
+		t.Fatalf("expected chain identity to be rewritten, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Fatalf("expected the old chain directory to be moved")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new", "data")); !os.IsNotExist(err) {
+		t.Fatalf("expected the blockchain data to be removed")
+	}
+
+	genesis := tests.FileContents(filepath.Join(dir, "new", "genesis.json"))
+	if !strings.Contains(genesis, `"chain_id": "new"`) || !strings.Contains(genesis, "9999999999999999") {
+		t.Fatalf("expected genesis file to be rewritten with numbers intact, got %q", genesis)
+	}
+	if config := tests.FileContents(filepath.Join(dir, "new", "config.toml")); config != "moniker = \"old\"\nchain_id = \"new\"\n" {
+		t.Fatalf("expected config file to have the new chain ID, got %q", config)
+	}
+}
+
+func TestChainsNewCluster(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	const cluster = "test-cluster"
+
+	do := def.NowDo()
+	do.Name = cluster
+	do.N = 3
+	if err := NewChain(do); err != nil {
+		t.Fatalf("expected cluster to be created, got %v", err)
+	}
+	defer func() {
+		for i := 0; i < 3; i++ {
+			os.Remove(filepath.Join(common.ChainsPath, ClusterNodeName(cluster, i)+".toml"))
+		}
+	}()
+
+	nodes := ClusterNodes(cluster)
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 cluster nodes, got %v", nodes)
+	}
+	for _, node := range nodes {
+		if !util.Running(def.TypeChain, node) {
+			t.Fatalf("expecting cluster node %s running", node)
+		}
+	}
+
+	do = def.NowDo()
+	do.Name = nodes[2]
+	do.Operations.Args = []string{"cat", path.Join(common.ErisContainerRoot, "chains", cluster, "config.toml")}
+	buf, err := data.ExecData(do)
+	if err != nil {
+		t.Fatalf("expected to read node config file, got %v", err)
+	}
+	if !strings.Contains(buf.String(), fmt.Sprintf(`seeds = "%s:46656,%s:46656"`, nodes[0], nodes[1])) {
+		t.Fatalf("expected node to be seeded with its peers, got %q", buf.String())
+	}
+
+	do = def.NowDo()
+	do.Name = cluster
+	if err := KillChain(do); err != nil {
+		t.Fatalf("expected cluster to be stopped, got %v", err)
+	}
+	for _, node := range nodes {
+		if util.Running(def.TypeChain, node) {
+			t.Fatalf("expecting cluster node %s stopped", node)
+		}
+	}
+
+	do = def.NowDo()
+	do.Name = cluster
+	do.RmD = true
+	if err := RemoveChain(do); err != nil {
+		t.Fatalf("expected cluster to be removed, got %v", err)
+	}
+	for _, node := range nodes {
+		if util.Exists(def.TypeChain, node) || util.Exists(def.TypeData, node) {
+			t.Fatalf("expecting cluster node %s removed", node)
+		}
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	config := []byte("moniker = \"old\"\nseeds = \"\"\n\n[section]\nseeds = \"nested\"\n")
+
+	config = setConfigValue(config, "seeds", "a:46656,b:46656")
+	config = setConfigValue(config, "fast_sync", "false")
+
+	expected := "fast_sync = \"false\"\nmoniker = \"old\"\nseeds = \"a:46656,b:46656\"\n\n[section]\nseeds = \"nested\"\n"
+	if string(config) != expected {
+		t.Fatalf("expected config %q, got %q", expected, config)
+	}
+}
+
+func TestRipemd160(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":                           "9c1185a5c5e9fc54612808977ee8f548b2258d31",
+		"abc":                        "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc",
+		strings.Repeat("a", 1000000): "52783243c1697bdbe16d37f97f68f08325dc1528",
+	} {
+		if hash := fmt.Sprintf("%x", ripemd160([]byte(input))); hash != expected {
+			t.Fatalf("expected RIPEMD-160 %s of a %d byte input, got %s", expected, len(input), hash)
+		}
+	}
+}
+
+func TestMakeChainAccountTypes(t *testing.T) {
+	const chain = "made-chain"
+	defer os.RemoveAll(filepath.Join(common.ChainsPath, chain))
+
+	if err := os.MkdirAll(common.AccountsTypePath, 0755); err != nil {
+		t.Fatalf("can't create the account types directory: %v", err)
+	}
+	accountType := filepath.Join(common.AccountsTypePath, "TestFull.toml")
+	contents := "name = \"TestFull\"\ndefault_number = 1\ndefault_tokens = 1000\ndefault_bond = 100\n\n[perms]\nroot = 1\nsend = 1\nbond = 0\n"
+	if err := ioutil.WriteFile(accountType, []byte(contents), 0644); err != nil {
+		t.Fatalf("can't write the account type: %v", err)
+	}
+	defer os.Remove(accountType)
+
+	var generated int
+	defer func(original func() (string, map[string]interface{}, error)) { generateKey = original }(generateKey)
+	generateKey = func() (string, map[string]interface{}, error) {
+		generated++
+		address := fmt.Sprintf("%040X", generated)
+		return address, map[string]interface{}{
+			"address": address,
+			"pub_key": []interface{}{1, fmt.Sprintf("%064X", generated)},
+		}, nil
+	}
+
+	do := def.NowDo()
+	do.Name = chain
+	do.AccountTypes = []string{"TestFull:2"}
+	if err := MakeChain(do); err != nil {
+		t.Fatalf("expected chain to be made, got %v", err)
+	}
+	if generated != 2 {
+		t.Fatalf("expected 2 keys generated, got %d", generated)
+	}
+
+	dir := filepath.Join(common.ChainsPath, chain)
+	genesis := tests.FileContents(filepath.Join(dir, "genesis.json"))
+	for _, expected := range []string{
+		`"chain_id": "made-chain"`,
+		`"name": "made-chain_testfull_001"`,
+		`"perms": 3,`,
+		`"set_bit": 35`,
+	} {
+		if !strings.Contains(genesis, expected) {
+			t.Fatalf("expected genesis file to contain %s, got %q", expected, genesis)
+		}
+	}
+	for _, file := range []string{
+		"config.toml",
+		"priv_validator.json",
+		"accounts.csv",
+		"validators.csv",
+		"addresses.csv",
+		filepath.Join("made-chain_testfull_000", "priv_validator.json"),
+		filepath.Join("made-chain_testfull_001", "genesis.json"),
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Fatalf("expected %s to be made, got %v", file, err)
+		}
+	}
+}
+
+func TestMakeChainKnown(t *testing.T) {
+	const (
+		chain  = "known-chain"
+		pubKey = "CB3688B7561D488A2A4834E1AEE9398BEF94844D8BDBBCA980C11E3654A45906"
+	)
+	defer os.RemoveAll(filepath.Join(common.ChainsPath, chain))
+
+	dir := filepath.Join(erisDir, "known")
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("can't create the CSV directory: %v", err)
+	}
+	validators := filepath.Join(dir, "validators.csv")
+	accounts := filepath.Join(dir, "accounts.csv")
+	if err := ioutil.WriteFile(validators, []byte(pubKey+",5000,val\n"), 0644); err != nil {
+		t.Fatalf("can't write validators: %v", err)
+	}
+	if err := ioutil.WriteFile(accounts, []byte(pubKey+",100000,acc,16383,16383\n"), 0644); err != nil {
+		t.Fatalf("can't write accounts: %v", err)
+	}
+
+	defer func(original func() (string, map[string]interface{}, error)) { generateKey = original }(generateKey)
+	generateKey = func() (string, map[string]interface{}, error) {
+		t.Fatalf("expected no keys generated for known accounts")
+		return "", nil, nil
+	}
+
+	do := def.NowDo()
+	do.Name = chain
+	do.ChainID = "known-id"
+	do.Known = true
+	do.ChainMakeVals = validators
+	do.ChainMakeActs = accounts
+	if err := MakeChain(do); err != nil {
+		t.Fatalf("expected chain to be made, got %v", err)
+	}
+
+	address, err := pubKeyAddress(pubKey)
+	if err != nil {
+		t.Fatalf("expected address of the public key, got %v", err)
+	}
+	genesis := tests.FileContents(filepath.Join(common.ChainsPath, chain, "genesis.json"))
+	for _, expected := range []string{
+		`"chain_id": "known-id"`,
+		`"address": "` + address + `"`,
+		`"amount": 5000`,
+		`"amount": 100000`,
+		`"perms": 16383`,
+	} {
+		if !strings.Contains(genesis, expected) {
+			t.Fatalf("expected genesis file to contain %s, got %q", expected, genesis)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(common.ChainsPath, chain, "priv_validator.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no priv_validator.json for known accounts")
+	}
+}
+
+func TestChainTypes(t *testing.T) {
+	do := def.NowDo()
+	do.Type = AccountTypes
+	do.Name = "TestValidator"
+	do.DefaultNumber = 2
+	do.Tokens = 1000
+	do.Bond = 10
+	do.Perms = []string{"bond", "root=0"}
+	if err := NewType(do); err != nil {
+		t.Fatalf("expected account type to be made, got %v", err)
+	}
+	defer os.Remove(filepath.Join(common.AccountsTypePath, "TestValidator.toml"))
+
+	if err := NewType(do); err == nil {
+		t.Fatalf("expected existing account type not to be overwritten")
+	}
+
+	accountType, err := loaders.LoadAccountType("testvalidator")
+	if err != nil {
+		t.Fatalf("expected account type to load, got %v", err)
+	}
+	expected := map[string]int{"bond": 1, "root": 0}
+	if accountType.DefaultNumber != 2 || accountType.DefaultBond != 10 || !reflect.DeepEqual(accountType.Perms, expected) {
+		t.Fatalf("expected account type to be written as requested, got %#v", accountType)
+	}
+
+	do = def.NowDo()
+	do.Type = ChainTypes
+	do.Name = "testchain"
+	do.AccountTypes = []string{"Unknown:1"}
+	if err := NewType(do); err == nil {
+		t.Fatalf("expected chain type of an unknown account type to fail")
+	}
+	do.AccountTypes = []string{"TestValidator:3"}
+	if err := NewType(do); err != nil {
+		t.Fatalf("expected chain type to be made, got %v", err)
+	}
+	if err := ValidateType(ChainTypes, "testchain"); err != nil {
+		t.Fatalf("expected chain type to be valid, got %v", err)
+	}
+
+	if err := RemoveType(do); err != nil {
+		t.Fatalf("expected chain type to be removed, got %v", err)
+	}
+	if err := ValidateType(ChainTypes, "testchain"); err == nil {
+		t.Fatalf("expected removed chain type not to be found")
+	}
+}
+
+func TestValidateAccountType(t *testing.T) {
+	for _, accountType := range []*def.AccountType{
+		{Name: "negative", DefaultTokens: -1},
+		{Name: "unknown", Perms: map[string]int{"fly": 1}},
+		{Name: "value", Perms: map[string]int{"send": 2}},
+	} {
+		if err := validateAccountType(accountType); err == nil {
+			t.Fatalf("expected account type %s to be invalid", accountType.Name)
+		}
+	}
+
+	if err := validateAccountType(&def.AccountType{Name: "valid", DefaultTokens: 1, Perms: map[string]int{"send": 1, "root": 0}}); err != nil {
+		t.Fatalf("expected account type to be valid, got %v", err)
+	}
+}
+
+func TestVerifyGenesis(t *testing.T) {
+	contents := []byte(`{"chain_id": "joined", "validators": [{"pub_key": [1, "ABCD"], "amount": 10}]}`)
+	const hash = "d6d5a3aa0a5a8e3bfc2e1fce33c02ed0e8bc44d5cc8ae1a4a89e4be0b2f2b4c8"
+
+	genesis, err := verifyGenesis(contents, "")
+	if err != nil {
+		t.Fatalf("expected genesis to be valid, got %v", err)
+	}
+	if genesis.ChainID != "joined" {
+		t.Fatalf("expected chain ID joined, got %q", genesis.ChainID)
+	}
+	if !isGenesisValidator(genesis, []byte(`{"type": 1, "data": "abcd"}`)) {
+		t.Fatalf("expected the key to be a genesis validator")
+	}
+	if isGenesisValidator(genesis, []byte(`[1, "0123"]`)) {
+		t.Fatalf("expected the key not to be a genesis validator")
+	}
+
+	if _, err := verifyGenesis(contents, hash); err == nil {
+		t.Fatalf("expected a wrong genesis hash to fail")
+	}
+	if _, err := verifyGenesis([]byte(`{"chain_id": "joined"}`), ""); err == nil {
+		t.Fatalf("expected genesis without validators to fail")
+	}
+}
+
+func TestParseSeeds(t *testing.T) {
+	seeds, err := parseSeeds([]string{"10.0.0.1:46656", " seed.example.com:46656", ""})
+	if err != nil {
+		t.Fatalf("expected seeds to parse, got %v", err)
+	}
+	if expected := []string{"10.0.0.1:46656", "seed.example.com:46656"}; !reflect.DeepEqual(seeds, expected) {
+		t.Fatalf("expected seeds %v, got %v", expected, seeds)
+	}
+
+	for _, seed := range []string{"10.0.0.1", ":46656", "10.0.0.1:port", "10.0.0.1:70000"} {
+		if _, err := parseSeeds([]string{seed}); err == nil {
+			t.Fatalf("expected seed %q to fail", seed)
+		}
+	}
+}
+
+func TestWriteJoinFiles(t *testing.T) {
+	dir := filepath.Join(erisDir, "join")
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(erisDir, "join.toml")
+	if err := ioutil.WriteFile(base, []byte("moniker = \"old\"\nchain_id = \"old\"\n"), 0644); err != nil {
+		t.Fatalf("can't write the base config: %v", err)
+	}
+	defer os.Remove(base)
+
+	genesis := []byte(`{"chain_id":"joined"}`)
+	priv := map[string]interface{}{"address": "1A2B"}
+	if err := writeJoinFiles(dir, "node", base, genesis, "joined", []string{"a:1", "b:2"}, priv); err != nil {
+		t.Fatalf("expected join files to be written, got %v", err)
+	}
+
+	if contents := tests.FileContents(filepath.Join(dir, "genesis.json")); contents != string(genesis) {
+		t.Fatalf("expected genesis file to be written as fetched, got %q", contents)
+	}
+	expected := "seeds = \"a:1,b:2\"\nmoniker = \"node\"\nchain_id = \"joined\"\n"
+	if config := tests.FileContents(filepath.Join(dir, "config.toml")); config != expected {
+		t.Fatalf("expected config %q, got %q", expected, config)
+	}
+	if key := tests.FileContents(filepath.Join(dir, "priv_validator.json")); !strings.Contains(key, "1A2B") {
+		t.Fatalf("expected node key to be written, got %q", key)
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	for _, value := range []struct {
+		key, value, raw string
+	}{
+		{"moniker", "node", `"node"`},
+		{"fast_sync", "1", "true"},
+		{"timeout_commit", "1000", "1000"},
+		{"log_level", "debug", `"debug"`},
+		{"seeds", "a:46656,tcp://b:46656", `"a:46656,tcp://b:46656"`},
+	} {
+		raw, err := configValue(value.key, value.value, false)
+		if err != nil {
+			t.Fatalf("expected %s=%s to pass, got %v", value.key, value.value, err)
+		}
+		if raw != value.raw {
+			t.Fatalf("expected %s=%s to be written as %s, got %s", value.key, value.value, value.raw, raw)
+		}
+	}
+
+	for _, pair := range [][2]string{
+		{"fast_sync", "maybe"},
+		{"timeout_commit", "soon"},
+		{"log_level", "loud"},
+		{"seeds", "a:46656,b"},
+		{"unknown", "value"},
+	} {
+		if _, err := configValue(pair[0], pair[1], false); err == nil {
+			t.Fatalf("expected %s=%s to fail", pair[0], pair[1])
+		}
+	}
+	if _, err := configValue("unknown", "value", true); err != nil {
+		t.Fatalf("expected an unknown key to pass with force, got %v", err)
+	}
+
+	if err := checkNodeConfig([]byte("fast_sync = \"true\"\nskip_upnp = false\n")); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	if err := checkNodeConfig([]byte("fast_sync = 1\n")); err == nil {
+		t.Fatalf("expected a numeric fast_sync to fail")
+	}
+	if err := checkNodeConfig([]byte("moniker = \n")); err == nil {
+		t.Fatalf("expected an invalid TOML document to fail")
+	}
+}
+
+func TestEventIDs(t *testing.T) {
+	events, err := EventIDs([]string{"block", "account:0x1a2b", "log:1A2B", "NewRound"})
+	if err != nil {
+		t.Fatalf("expected filters to pass, got %v", err)
+	}
+
+	expected := []string{"NewBlock", "Acc/1A2B/Input", "Acc/1A2B/Output", "Log/1A2B", "NewRound"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+
+	if _, err := EventIDs([]string{"log:"}); err == nil {
+		t.Fatalf("expected a filter without an address to fail")
+	}
+}
+
+func TestPrintEnv(t *testing.T) {
+	vars := []*EnvVar{
+		{"ERIS_CHAIN_NAME", "test"},
+		{"ERIS_CHAIN_URL", "http://192.168.99.100:46657"},
+		{"ERIS_QUOTED", "it's"},
+	}
+
+	for format, expected := range map[string]string{
+		"shell":  "export ERIS_CHAIN_NAME='test'\nexport ERIS_CHAIN_URL='http://192.168.99.100:46657'\nexport ERIS_QUOTED='it'\\''s'\n",
+		"dotenv": "ERIS_CHAIN_NAME=test\nERIS_CHAIN_URL=http://192.168.99.100:46657\nERIS_QUOTED=it's\n",
+		"json":   "{\n  \"ERIS_CHAIN_NAME\": \"test\",\n  \"ERIS_CHAIN_URL\": \"http://192.168.99.100:46657\",\n  \"ERIS_QUOTED\": \"it's\"\n}\n",
+	} {
+		buf := new(bytes.Buffer)
+		if err := PrintEnv(buf, vars, format); err != nil {
+			t.Fatalf("unexpected error printing %s: %v", format, err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("expected %s output %q, got %q", format, expected, buf.String())
+		}
+	}
+
+	if err := PrintEnv(new(bytes.Buffer), vars, "xml"); err == nil {
+		t.Fatalf("expected an unknown format error")
+	}
+}
+
 func TestServiceLinkNoChain(t *testing.T) {
 	defer tests.RemoveAllContainers()
 
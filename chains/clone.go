@@ -0,0 +1,236 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/services"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+var configChainID = regexp.MustCompile(`(?m)^(\s*chain_id\s*=\s*)"[^"]*"`)
+
+// CloneChain copies the source chain data container into a new data
+// container and writes a new chain definition file into ChainsPath.
+// A running source chain is stopped for the duration of the copy and
+// restarted afterwards. The new chain isn't started.
+//
+// If the chain ID or the validator key is rewritten, the blockchain
+// data of the copy is reset to the genesis state, because the existing
+// blocks are tied to the original chain ID and validators.
+//
+//  do.Name    - name of the source chain (required)
+//  do.NewName - name of the new chain (required)
+//  do.ChainID - chain ID of the new chain (defaults to the source chain ID)
+//  do.NewKeys - generate a new validator key for the new chain (optional)
+//  do.Timeout - time to wait for the source chain container to stop
+//
+func CloneChain(do *definitions.Do) error {
+	if do.Name == do.NewName {
+		return fmt.Errorf("Cannot clone a chain into itself")
+	}
+
+	chain, err := loaders.LoadChainDefinition(do.Name)
+	if err != nil {
+		return err
+	}
+	if !util.IsData(chain.Name) {
+		return fmt.Errorf("Chain %s has no data container to clone", chain.Name)
+	}
+	if util.IsData(do.NewName) || util.IsKnownChain(do.NewName) {
+		return fmt.Errorf("Chain %s already exists. Please choose a different name", do.NewName)
+	}
+
+	chainID := do.ChainID
+	if chainID == "" {
+		chainID = chain.ChainID
+	}
+
+	log.WithFields(log.Fields{
+		"from":     chain.Name,
+		"to":       do.NewName,
+		"chain id": chainID,
+	}).Warn("Cloning chain")
+
+	dir, err := ioutil.TempDir("", "eris_clone_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := whileStopped(do, chain, func() error {
+		return exportChainData(chain.Name, dir)
+	}); err != nil {
+		return err
+	}
+
+	if chainID != chain.ChainID || do.NewKeys {
+		if err := rewriteChainIdentity(filepath.Join(dir, "chains"), chain.ChainID, chainID, do.NewKeys); err != nil {
+			return err
+		}
+	}
+
+	if err := importChainData(do.NewName, dir); err != nil {
+		return err
+	}
+
+	// The definition file is copied as is, without resolving
+	// its variables, extends, and overlays (see RenameChain).
+	log.WithField("=>", do.NewName).Debug("Writing chain definition file")
+	definition, err := config.LoadViperConfig(ChainsPath, do.Name)
+	if err != nil {
+		return err
+	}
+	if chainID != chain.ChainID {
+		definition.Set("chain_id", chainID)
+	}
+	newFile := filepath.Join(ChainsPath, do.NewName+filepath.Ext(definition.ConfigFileUsed()))
+	if err := config.RenameDefinitionFile(definition, do.NewName, newFile); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// rewriteChainIdentity moves the chain directory to the new chain ID
+// (if it differs) and updates the chain ID in genesis.json and config.toml.
+// If newKeys is true, priv_validator.json is replaced with a key generated
+// by the keys service and the validator entry in genesis.json is updated.
+// The blockchain data is removed.
+func rewriteChainIdentity(chainsDir, oldID, newID string, newKeys bool) error {
+	oldDir := filepath.Join(chainsDir, oldID)
+	newDir := filepath.Join(chainsDir, newID)
+
+	if oldDir != newDir {
+		if err := os.Rename(oldDir, newDir); err != nil {
+			return fmt.Errorf("Cannot find the %s chain directory in the data container: %v", oldID, err)
+		}
+	}
+
+	log.WithField("dir", filepath.Join(newDir, "data")).Warn("Resetting blockchain data of the clone")
+	if err := os.RemoveAll(filepath.Join(newDir, "data")); err != nil {
+		return err
+	}
+
+	genesis := make(map[string]interface{})
+	if err := readJSON(filepath.Join(newDir, "genesis.json"), &genesis); err != nil {
+		return err
+	}
+	genesis["chain_id"] = newID
+
+	if newKeys {
+		if err := rewriteValidatorKey(newDir, genesis); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(filepath.Join(newDir, "genesis.json"), genesis); err != nil {
+		return err
+	}
+
+	configFile := filepath.Join(newDir, "config.toml")
+	config, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	config = configChainID.ReplaceAll(config, []byte(fmt.Sprintf(`${1}"%s"`, newID)))
+	return ioutil.WriteFile(configFile, config, 0644)
+}
+
+// rewriteValidatorKey generates a new validator key with the keys
+// service, writes it to priv_validator.json in the chainDir directory,
+// and replaces the old validator public key in the genesis document.
+func rewriteValidatorKey(chainDir string, genesis map[string]interface{}) error {
+	privFile := filepath.Join(chainDir, "priv_validator.json")
+
+	oldPriv := make(map[string]interface{})
+	if err := readJSON(privFile, &oldPriv); err != nil {
+		return err
+	}
+
+	address, newPriv, err := generateValidatorKey()
+	if err != nil {
+		return err
+	}
+
+	validators, _ := genesis["validators"].([]interface{})
+	for _, v := range validators {
+		validator, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(validator["pub_key"], oldPriv["pub_key"]) {
+			validator["pub_key"] = newPriv["pub_key"]
+		}
+	}
+
+	log.WithField("address", address).Warn("Writing the new validator key")
+	return writeJSON(privFile, newPriv)
+}
+
+// generateValidatorKey generates a new key with the keys service and
+// returns its address and the priv_validator.json document for it.
+func generateValidatorKey() (string, map[string]interface{}, error) {
+	if err := checkKeysRunningOrStart(); err != nil {
+		return "", nil, err
+	}
+
+	log.Warn("Generating a new validator key")
+	buf, err := services.ExecHandler("keys", []string{"eris-keys", "gen", "--no-pass"})
+	if err != nil {
+		return "", nil, err
+	}
+	address := strings.TrimSpace(buf.String())
+
+	buf, err = services.ExecHandler("keys", []string{"mintkey", "mint", address})
+	if err != nil {
+		return "", nil, err
+	}
+
+	priv := make(map[string]interface{})
+	decoder := json.NewDecoder(buf)
+	decoder.UseNumber()
+	if err := decoder.Decode(&priv); err != nil {
+		return "", nil, fmt.Errorf("Cannot read the validator key %s generated by the keys service: %v", address, err)
+	}
+	return address, priv, nil
+}
+
+// readJSON decodes the JSON file keeping numbers intact (big
+// amounts in the genesis document don't fit into float64).
+func readJSON(fileName string, v interface{}) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("Cannot read %s: %v", filepath.Base(fileName), err)
+	}
+	return nil
+}
+
+func writeJSON(fileName string, v interface{}) error {
+	mar, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, append(mar, '\n'), 0600)
+}
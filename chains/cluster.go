@@ -0,0 +1,337 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+var configTable = regexp.MustCompile(`(?m)^[ \t]*\[`)
+
+// Validator bond used if the base genesis has no validators.
+const defaultValidatorAmount = "5000000000"
+
+// ClusterNodeName returns the chain name of the i-th node of the cluster.
+func ClusterNodeName(cluster string, i int) string {
+	return fmt.Sprintf("%s_%d", cluster, i)
+}
+
+// ClusterNodes returns the chain names of the cluster nodes created
+// by [eris chains new NAME --nodes N] in the order of their creation,
+// or nil if name is not a cluster.
+func ClusterNodes(name string) []string {
+	var nodes []string
+	for i := 0; ; i++ {
+		node := ClusterNodeName(name, i)
+		if !util.IsKnownChain(node) {
+			break
+		}
+		chain, err := loaders.LoadChainDefinition(node)
+		if err != nil || chain.Cluster != name {
+			break
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// isCluster returns true if name is a cluster rather than a single chain.
+func isCluster(name string) bool {
+	return !util.IsKnownChain(name) && len(ClusterNodes(name)) > 0
+}
+
+// newCluster creates and starts a local chain of do.N validator nodes
+// named NAME_0..NAME_N-1. Every node gets its own validator key generated
+// by the keys service, its own data container and chain definition file,
+// and a shared genesis listing all the validators. Every node is seeded
+// with the nodes created before it.
+//
+//  do.Name        - name of the cluster (required)
+//  do.N           - number of nodes (required)
+//  do.ChainID     - chain ID shared by the nodes (defaults to do.Name)
+//  do.Path        - directory with the base genesis.json and config.toml
+//                   (defaults to ~/.eris/chains/default)
+//  do.GenesisFile - base genesis.json (optional)
+//  do.ConfigFile  - base config.toml (optional)
+//
+// The first node publishes ports according to the definition file and the
+// --ports flag, the others publish their ports to random host ports.
+func newCluster(do *definitions.Do) (err error) {
+	if util.IsKnownChain(do.Name) || isCluster(do.Name) {
+		return fmt.Errorf("Chain %s already exists. Please choose a different name", do.Name)
+	}
+
+	nodes := make([]string, do.N)
+	for i := range nodes {
+		nodes[i] = ClusterNodeName(do.Name, i)
+		if util.IsKnownChain(nodes[i]) || util.IsData(nodes[i]) {
+			return fmt.Errorf("Chain %s already exists. Please choose a different cluster name", nodes[i])
+		}
+	}
+
+	base := do.Path
+	if base == "" {
+		base = "default"
+	}
+	if src, err := os.Stat(base); err != nil || !src.IsDir() {
+		if base, err = util.ChainsPathChecker(base); err != nil {
+			return err
+		}
+	}
+
+	chainID := do.ChainID
+	if chainID == "" {
+		chainID = do.Name
+	}
+
+	genesisFile := resolveGenesisFile(do.GenesisFile, base)
+	if genesisFile == "" {
+		return fmt.Errorf("Cannot find genesis.json in %s. Please use the --genesis or --dir flag", base)
+	}
+	genesis := make(map[string]interface{})
+	if err := readJSON(genesisFile, &genesis); err != nil {
+		return err
+	}
+	genesis["chain_id"] = chainID
+
+	configFile := do.ConfigFile
+	if configFile == "" {
+		configFile = filepath.Join(base, "config.toml")
+	}
+	baseConfig, err := ioutil.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"=>":       do.Name,
+		"nodes":    do.N,
+		"chain id": chainID,
+	}).Warn("Creating chain cluster")
+
+	amount := validatorAmount(genesis)
+	validators := make([]interface{}, len(nodes))
+	privs := make([]map[string]interface{}, len(nodes))
+	for i := range nodes {
+		address, priv, err := generateValidatorKey()
+		if err != nil {
+			return err
+		}
+		privs[i] = priv
+		validators[i] = map[string]interface{}{
+			"pub_key": priv["pub_key"],
+			"amount":  amount,
+			"unbond_to": []interface{}{
+				map[string]interface{}{
+					"address": address,
+					"amount":  amount,
+				},
+			},
+		}
+	}
+	genesis["validators"] = validators
+
+	dir, err := ioutil.TempDir("", "eris_cluster_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Don't leave a half-made cluster behind.
+	var created []string
+	defer func() {
+		if err != nil {
+			removeClusterNodes(created)
+		}
+	}()
+
+	for i, node := range nodes {
+		nodeDir := filepath.Join(dir, node)
+		if err := Copy(base, nodeDir); err != nil {
+			return err
+		}
+		if err := writeJSON(filepath.Join(nodeDir, "genesis.json"), genesis); err != nil {
+			return err
+		}
+		if err := writeJSON(filepath.Join(nodeDir, "priv_validator.json"), privs[i]); err != nil {
+			return err
+		}
+
+		seeds := make([]string, i)
+		for j := range seeds {
+			peer, err := loaders.LoadChainDefinition(nodes[j])
+			if err != nil {
+				return err
+			}
+			if seeds[j], err = util.LinkedEndpoint(nodes[j], peer.Service, definitions.EndpointP2P); err != nil {
+				return err
+			}
+		}
+		nodeConfig := setConfigValue(baseConfig, "moniker", node)
+		nodeConfig = setConfigValue(nodeConfig, "seeds", strings.Join(seeds, ","))
+		nodeConfig = configChainID.ReplaceAll(nodeConfig, []byte(fmt.Sprintf(`${1}"%s"`, chainID)))
+		if err := ioutil.WriteFile(filepath.Join(nodeDir, "config.toml"), nodeConfig, 0644); err != nil {
+			return err
+		}
+
+		if err := writeClusterNodeDefinition(do.Name, node, chainID, nodes[:i]); err != nil {
+			return err
+		}
+
+		nodeDo := *do
+		nodeDo.Name = node
+		nodeDo.ChainID = chainID
+		nodeDo.Path = nodeDir
+		nodeDo.GenesisFile = ""
+		nodeDo.ConfigFile = ""
+		nodeDo.Priv = ""
+		nodeDo.N = 0
+		// The validators can't make blocks until most of them are up.
+		nodeDo.Wait = false
+		ops := *do.Operations
+		if i > 0 {
+			ops.PublishAllPorts = true
+		}
+		nodeDo.Operations = &ops
+
+		created = append(created, node)
+		log.WithField("=>", node).Warn("Starting cluster node")
+		if err := NewChain(&nodeDo); err != nil {
+			return err
+		}
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// writeClusterNodeDefinition writes the chain definition file of the
+// cluster node. The node depends on (and is linked to) the peers nodes,
+// so that it can reach them by their names.
+func writeClusterNodeDefinition(cluster, node, chainID string, peers []string) (err error) {
+	chain := loaders.MockChainDefinition(node, chainID)
+	chain.Cluster = cluster
+	if len(peers) > 0 {
+		chain.Dependencies = &definitions.Dependencies{}
+		for _, peer := range peers {
+			chain.Dependencies.Chains = append(chain.Dependencies.Chains, peer+":"+peer+":l")
+		}
+	}
+
+	chain.Maintainer.Name, chain.Maintainer.Email, err = config.GitConfigUser()
+	if err != nil {
+		log.Debug(err.Error())
+	}
+
+	return WriteChainDefinitionFile(chain, filepath.Join(ChainsPath, node+".toml"))
+}
+
+// killCluster stops (and removes if do.Rm is set) the cluster
+// nodes in the reverse order of their creation.
+func killCluster(do *definitions.Do) error {
+	nodes := ClusterNodes(do.Name)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		nodeDo := *do
+		nodeDo.Name = nodes[i]
+		if err := KillChain(&nodeDo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitCluster waits for every cluster node to produce blocks.
+func waitCluster(do *definitions.Do) error {
+	for _, node := range ClusterNodes(do.Name) {
+		nodeDo := *do
+		nodeDo.Name = node
+		if err := WaitChain(&nodeDo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeCluster removes the cluster nodes in the
+// reverse order of their creation.
+func removeCluster(do *definitions.Do) error {
+	nodes := ClusterNodes(do.Name)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		nodeDo := *do
+		nodeDo.Name = nodes[i]
+		if err := RemoveChain(&nodeDo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeClusterNodes removes the containers and definition
+// files of the nodes. Errors are logged and ignored.
+func removeClusterNodes(nodes []string) {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		log.WithField("=>", nodes[i]).Warn("Removing cluster node")
+		do := definitions.NowDo()
+		do.Name = nodes[i]
+		do.Rm = true
+		do.RmD = true
+		do.Volumes = true
+		do.Force = true
+		if err := KillChain(do); err != nil {
+			log.Error(err)
+		}
+		if err := os.Remove(filepath.Join(ChainsPath, nodes[i]+".toml")); err != nil && !os.IsNotExist(err) {
+			log.Error(err)
+		}
+	}
+}
+
+// validatorAmount returns the bond amount of the first
+// validator in the genesis document or the default one.
+func validatorAmount(genesis map[string]interface{}) interface{} {
+	if validators, ok := genesis["validators"].([]interface{}); ok && len(validators) > 0 {
+		if validator, ok := validators[0].(map[string]interface{}); ok && validator["amount"] != nil {
+			return validator["amount"]
+		}
+	}
+	return json.Number(defaultValidatorAmount)
+}
+
+// setConfigValue sets the top level key in the TOML config to
+// a string value. The key is added if it's missing.
+func setConfigValue(config []byte, key, value string) []byte {
+	return setConfigRaw(config, key, fmt.Sprintf("%q", value))
+}
+
+// setConfigRaw sets the top level key in the TOML config to
+// the raw TOML value. The key is added if it's missing.
+func setConfigRaw(config []byte, key, raw string) []byte {
+	top, tables := config, []byte{}
+	if loc := configTable.FindIndex(config); loc != nil {
+		top, tables = config[:loc[0]], config[loc[0]:]
+	}
+
+	re := regexp.MustCompile(`(?m)^([ \t]*` + regexp.QuoteMeta(key) + `[ \t]*=[ \t]*).*$`)
+	if re.Match(top) {
+		top = re.ReplaceAllFunc(top, func(line []byte) []byte {
+			prefix := re.FindSubmatch(line)[1]
+			return append(append([]byte{}, prefix...), raw...)
+		})
+	} else {
+		top = append([]byte(key+" = "+raw+"\n"), top...)
+	}
+	return append(top, tables...)
+}
@@ -0,0 +1,252 @@
+package chains
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// EnvVar is an environment variable exported by [eris env].
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvChain displays the environment variables applications need to connect
+// to the running chain, the keys server, and the services: host:port
+// addresses of the named endpoints (see util.Endpoints), the chain ID,
+// and the default account.
+//
+//  do.Name          - name of the chain (defaults to the checked out chain)
+//  do.ServicesSlice - services to export the endpoints of besides keys
+//  do.Format        - "shell" (or "") for export lines to be evaluated
+//                     by the shell, "dotenv" for NAME=VALUE lines,
+//                     or "json"
+//
+func EnvChain(do *definitions.Do) error {
+	name := do.Name
+	if name == "" {
+		head, err := util.GetHead()
+		if err != nil {
+			return err
+		}
+		if head == "" {
+			return fmt.Errorf("No chain given and no chain is checked out. Please give a chain name or use [eris chains checkout]")
+		}
+		name = head
+	}
+
+	vars, err := ChainEnv(name, do.ServicesSlice)
+	if err != nil {
+		return err
+	}
+	return PrintEnv(config.GlobalConfig.Writer, vars, do.Format)
+}
+
+// ChainEnv returns the environment variables for the running chain
+// and services (see EnvChain):
+//
+//  ERIS_CHAIN_NAME        - name of the chain
+//  ERIS_CHAIN_ID          - chain ID from the genesis document
+//  ERIS_CHAIN_HOST        - host the chain RPC server is reachable at
+//  ERIS_CHAIN_URL         - URL of the chain RPC server
+//  ERIS_CHAIN_<ENDPOINT>  - host:port addresses of the chain endpoints
+//  ERIS_DEFAULT_ACCOUNT   - the first full (or else the first) account
+//                           made with the chain
+//  ERIS_KEYS_URL          - URL of the keys server, if it's running
+//  ERIS_<SERVICE>_URL     - URL of the service "api" endpoint
+//  ERIS_<SERVICE>_<ENDPOINT> - host:port addresses of the service endpoints
+//
+// Addresses are resolved from the Docker port bindings and the Docker
+// host, so they are reachable from the host eris runs on.
+func ChainEnv(name string, services []string) ([]*EnvVar, error) {
+	chain, err := loaders.LoadChainDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+	if !util.IsChain(chain.Name, true) {
+		return nil, fmt.Errorf("Chain %s is not running. Please start it with [eris chains start %[1]s]", name)
+	}
+
+	vars := []*EnvVar{{"ERIS_CHAIN_NAME", chain.Name}}
+
+	endpoints := util.Endpoints(chain.Service, chain.Operations)
+	for _, endpoint := range endpoints {
+		if endpoint.Name != definitions.EndpointRPC || endpoint.Address == "" {
+			continue
+		}
+		host, _, err := net.SplitHostPort(endpoint.Address)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars,
+			&EnvVar{"ERIS_CHAIN_HOST", host},
+			&EnvVar{"ERIS_CHAIN_URL", "http://" + endpoint.Address},
+		)
+	}
+	vars = append(vars, endpointVars("CHAIN", endpoints)...)
+
+	client, err := ChainClient(name)
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := client.Genesis()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get the genesis of chain %s: %v", name, err)
+	}
+	vars = append(vars, &EnvVar{"ERIS_CHAIN_ID", genesis.ChainID})
+
+	account := defaultAccount(chain.Name)
+	if account == "" && len(genesis.Accounts) > 0 {
+		account = genesis.Accounts[0].Address
+	}
+	if account != "" {
+		vars = append(vars, &EnvVar{"ERIS_DEFAULT_ACCOUNT", account})
+	}
+
+	keys, err := serviceVars("keys")
+	if err != nil {
+		log.WithField("=>", "keys").Warnf("Not exporting the keys server address: %v", err)
+	}
+	vars = append(vars, keys...)
+
+	for _, service := range services {
+		if service == "keys" {
+			continue
+		}
+		more, err := serviceVars(service)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, more...)
+	}
+
+	return vars, nil
+}
+
+// PrintEnv writes the environment variables to w in the format
+// (see EnvChain).
+func PrintEnv(w io.Writer, vars []*EnvVar, format string) error {
+	switch format {
+	case "", "shell":
+		for _, v := range vars {
+			if _, err := fmt.Fprintf(w, "export %s=%s\n", v.Name, shellQuote(v.Value)); err != nil {
+				return err
+			}
+		}
+	case "dotenv":
+		for _, v := range vars {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", v.Name, v.Value); err != nil {
+				return err
+			}
+		}
+	case "json":
+		env := make(map[string]string)
+		for _, v := range vars {
+			env[v.Name] = v.Value
+		}
+		mar, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(mar, '\n'))
+		return err
+	default:
+		return fmt.Errorf("Unknown format %q. Please use shell, dotenv, or json", format)
+	}
+	return nil
+}
+
+// serviceVars returns the URL and the endpoint addresses
+// of the running service.
+func serviceVars(name string) ([]*EnvVar, error) {
+	service, err := loaders.LoadServiceDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+	if !util.IsService(service.Service.Name, true) {
+		return nil, fmt.Errorf("Service %s is not running. Please start it with [eris services start %[1]s]", name)
+	}
+
+	prefix := envName(name)
+
+	var vars []*EnvVar
+	endpoints := util.Endpoints(service.Service, service.Operations)
+	for _, endpoint := range endpoints {
+		if endpoint.Name == definitions.EndpointAPI && endpoint.Address != "" {
+			vars = append(vars, &EnvVar{"ERIS_" + prefix + "_URL", "http://" + endpoint.Address})
+			break
+		}
+	}
+	return append(vars, endpointVars(prefix, endpoints)...), nil
+}
+
+func endpointVars(prefix string, endpoints []*util.Endpoint) []*EnvVar {
+	var vars []*EnvVar
+	for _, endpoint := range endpoints {
+		if endpoint.Address == "" {
+			continue
+		}
+		vars = append(vars, &EnvVar{"ERIS_" + prefix + "_" + envName(endpoint.Name), endpoint.Address})
+	}
+	return vars
+}
+
+// defaultAccount returns the first full (or else the first) account
+// from the addresses.csv file [eris chains make] writes into the chain
+// directory, or "" if there's no such file.
+func defaultAccount(name string) string {
+	file, err := os.Open(filepath.Join(ChainsPath, name, "addresses.csv"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var first string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ",")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		if strings.Contains(fields[1], "_full_") {
+			return fields[0]
+		}
+		if first == "" {
+			first = fields[0]
+		}
+	}
+	return first
+}
+
+// envName turns a service or an endpoint name into
+// an environment variable name component.
+func envName(name string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z':
+			return c - 'a' + 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
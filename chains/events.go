@@ -0,0 +1,151 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/rpc"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// Time to wait before reconnecting to the chain.
+var eventsReconnectInterval = 2 * time.Second
+
+// EventsChain subscribes to the running chain events and displays them as
+// they arrive until interrupted. If an established subscription is lost
+// (e.g. the chain container is restarted), EventsChain waits for the chain
+// and subscribes again. Errors before the first subscription (an unknown
+// chain, a chain that isn't running) are returned right away.
+//
+//  do.Name   - name of the chain (required)
+//  do.Filter - events to subscribe to (defaults to new blocks), see EventIDs
+//  do.JSON   - display every event as a JSON document on its own line
+//              rather than a summary
+//
+func EventsChain(do *definitions.Do) error {
+	events, err := EventIDs(do.Filter)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"=>":     do.Name,
+		"events": events,
+	}).Info("Subscribing to chain events")
+
+	chain, err := loaders.LoadChainDefinition(do.Name)
+	if err != nil {
+		return err
+	}
+
+	printEvent := printEventSummary
+	if do.JSON {
+		printEvent = printEventJSON
+	}
+	var printErr error
+	handler := func(event *rpc.Event) error {
+		printErr = printEvent(event)
+		return printErr
+	}
+
+	subscribed := false
+	for {
+		client, err := chainClient(chain)
+		if err == nil {
+			err = client.Subscribe(events, handler)
+		}
+		if printErr != nil {
+			return printErr
+		}
+
+		if _, ok := err.(*rpc.ConnectionLostError); ok {
+			subscribed = true
+			log.WithField("=>", do.Name).Warnf("Lost connection to chain (%v). Reconnecting", err)
+		} else if !subscribed {
+			return err
+		} else {
+			log.WithField("=>", do.Name).Debugf("Chain is not available yet (%v)", err)
+		}
+		time.Sleep(eventsReconnectInterval)
+	}
+}
+
+// EventIDs translates the filters into the chain event IDs:
+//
+//  block            - new blocks ("NewBlock")
+//  account:ADDRESS  - transactions sending from and to the account
+//                     ("Acc/ADDRESS/Input" and "Acc/ADDRESS/Output")
+//  call:ADDRESS     - calls of the contract ("AccCall/ADDRESS")
+//  log:ADDRESS      - events emitted by the contract ("Log/ADDRESS")
+//
+// Other filters are passed through as event IDs. No filters mean
+// new blocks.
+func EventIDs(filters []string) ([]string, error) {
+	if len(filters) == 0 {
+		return []string{"NewBlock"}, nil
+	}
+
+	var events []string
+	for _, filter := range filters {
+		kind, address := filter, ""
+		if i := strings.Index(filter, ":"); i >= 0 {
+			kind, address = filter[:i], strings.ToUpper(strings.TrimPrefix(filter[i+1:], "0x"))
+			if address == "" {
+				return nil, fmt.Errorf("Filter %q needs an address", filter)
+			}
+		}
+
+		switch kind {
+		case "block":
+			events = append(events, "NewBlock")
+		case "account":
+			events = append(events, "Acc/"+address+"/Input", "Acc/"+address+"/Output")
+		case "call":
+			events = append(events, "AccCall/"+address)
+		case "log":
+			events = append(events, "Log/"+address)
+		default:
+			events = append(events, filter)
+		}
+	}
+	return events, nil
+}
+
+func printEventJSON(event *rpc.Event) error {
+	mar, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = config.GlobalConfig.Writer.Write(append(mar, '\n'))
+	return err
+}
+
+func printEventSummary(event *rpc.Event) error {
+	_, err := fmt.Fprintf(config.GlobalConfig.Writer, "%s %s %s\n", time.Now().Format("15:04:05"), event.Event, EventSummary(event))
+	return err
+}
+
+// EventSummary returns a short description of the event data.
+func EventSummary(event *rpc.Event) string {
+	if event.Event == "NewBlock" {
+		var data struct {
+			Block *rpc.Block `json:"block"`
+		}
+		if err := json.Unmarshal(event.Data, &data); err == nil && data.Block != nil && data.Block.Header != nil {
+			return fmt.Sprintf("height=%d txs=%d", data.Block.Header.Height, data.Block.Header.NumTxs)
+		}
+	}
+
+	const maxSummary = 120
+	summary := string(event.Data)
+	if len(summary) > maxSummary {
+		summary = summary[:maxSummary] + "..."
+	}
+	return summary
+}
@@ -0,0 +1,230 @@
+package chains
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/rpc"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// JoinChain starts a node of an existing network. The genesis file is
+// fetched from a location (see loaders.Resolvers) and verified, the node
+// key is generated by the keys service (or imported), and the
+// ~/.eris/chains/NAME directory is filled with the genesis.json,
+// priv_validator.json, and config.toml (with the seeds) files the node
+// is then started from (see NewChain).
+//
+// The node joins as an observer unless its key is one of the genesis
+// validators. With do.Validator the node is required to be a validator:
+// if the key isn't in the genesis yet, the files are still written, and
+// the error explains how to become one.
+//
+//  do.Name        - name of the chain (required)
+//  do.GenesisFile - location of the genesis file (required)
+//  do.GenesisHash - expected hex encoded SHA256 hash of the genesis file (optional)
+//  do.Seeds       - host:port addresses of the network nodes (optional)
+//  do.Priv        - priv_validator.json of the node key (generated if not given) (optional)
+//  do.Validator   - require the node to be a validator (optional)
+//  do.ChainID     - expected chain ID (optional)
+//  do.ConfigFile  - base config.toml (defaults to ~/.eris/chains/default/config.toml) (optional)
+//  do.ConfigOpts  - KEY=VALUE config options passed to the node (optional)
+//  do.Gateway     - node address (optional)
+//
+func JoinChain(do *definitions.Do) error {
+	if util.IsKnownChain(do.Name) || util.IsData(do.Name) {
+		return fmt.Errorf("Chain %s already exists. Please choose a different name", do.Name)
+	}
+	if do.GenesisFile == "" {
+		return fmt.Errorf("A genesis file location is required to join a chain. Please use the --genesis flag")
+	}
+
+	seeds, err := parseSeeds(do.Seeds)
+	if err != nil {
+		return err
+	}
+
+	contents, _, err := loaders.FetchFile(do.GenesisFile)
+	if err != nil {
+		return fmt.Errorf("Cannot fetch the genesis file from %s: %v", do.GenesisFile, err)
+	}
+	genesis, err := verifyGenesis(contents, do.GenesisHash)
+	if err != nil {
+		return err
+	}
+	if do.ChainID != "" && do.ChainID != genesis.ChainID {
+		return fmt.Errorf("Expected the %s chain ID, the genesis file has %s", do.ChainID, genesis.ChainID)
+	}
+
+	var priv map[string]interface{}
+	if do.Priv != "" {
+		priv, err = readValidatorKey(do.Priv)
+	} else {
+		_, priv, err = generateKey()
+	}
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(ChainsPath, do.Name)
+	if err := writeJoinFiles(dir, do.Name, do.ConfigFile, contents, genesis.ChainID, seeds, priv); err != nil {
+		return err
+	}
+
+	pubKey, err := json.Marshal(priv["pub_key"])
+	if err != nil {
+		return err
+	}
+	validator := isGenesisValidator(genesis, rpc.PubKey(pubKey))
+	if do.Validator && !validator {
+		return fmt.Errorf(`The node key is not a validator of the %s chain. Please ask the network
+maintainers to add the validators.csv line
+
+  %s,BOND,%s
+
+to the genesis (see [eris chains make --known]) and join again with the
+--priv %s flag`, genesis.ChainID, rpc.PubKey(pubKey).String(), do.Name, filepath.Join(dir, "priv_validator.json"))
+	}
+
+	role := "observer"
+	if validator {
+		role = "validator"
+	}
+	log.WithFields(log.Fields{
+		"=>":       do.Name,
+		"chain id": genesis.ChainID,
+		"seeds":    strings.Join(seeds, ","),
+		"address":  priv["address"],
+	}).Warn("Joining chain as " + role)
+
+	do.Path = dir
+	do.ChainID = genesis.ChainID
+	do.GenesisFile = ""
+	do.ConfigFile = ""
+	do.Priv = ""
+	do.N = 0
+	if err := NewChain(do); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// verifyGenesis checks the genesis file hash (if expected is given)
+// and that it has a chain ID and validators.
+func verifyGenesis(contents []byte, expected string) (*rpc.Genesis, error) {
+	sum := sha256.Sum256(contents)
+	hash := hex.EncodeToString(sum[:])
+	log.WithField("hash", hash).Info("Genesis file fetched")
+
+	if expected != "" && !strings.EqualFold(strings.TrimPrefix(expected, "0x"), hash) {
+		return nil, fmt.Errorf("The genesis file hash %s doesn't match the expected %s", hash, expected)
+	}
+
+	genesis := new(rpc.Genesis)
+	if err := json.Unmarshal(contents, genesis); err != nil {
+		return nil, fmt.Errorf("Cannot read the genesis file: %v", err)
+	}
+	if genesis.ChainID == "" {
+		return nil, fmt.Errorf("The genesis file has no chain ID")
+	}
+	if len(genesis.Validators) == 0 {
+		return nil, fmt.Errorf("The genesis file has no validators")
+	}
+	return genesis, nil
+}
+
+// parseSeeds checks the seeds are host:port addresses.
+func parseSeeds(seeds []string) ([]string, error) {
+	var parsed []string
+	for _, seed := range seeds {
+		seed = strings.TrimSpace(seed)
+		if seed == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(seed)
+		if err != nil || host == "" {
+			return nil, fmt.Errorf("Invalid seed %q. Please use the HOST:PORT format, e.g. 10.0.0.1:46656", seed)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("Invalid seed port in %q", seed)
+		}
+		parsed = append(parsed, seed)
+	}
+	return parsed, nil
+}
+
+// readValidatorKey reads the priv_validator.json file of the node key.
+func readValidatorKey(fileName string) (map[string]interface{}, error) {
+	priv := make(map[string]interface{})
+	if err := readJSON(fileName, &priv); err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"address", "pub_key", "priv_key"} {
+		if priv[field] == nil {
+			return nil, fmt.Errorf("The %s node key has no %q field", fileName, field)
+		}
+	}
+	return priv, nil
+}
+
+// isGenesisValidator returns true if the key is one
+// of the validators listed in the genesis.
+func isGenesisValidator(genesis *rpc.Genesis, pubKey rpc.PubKey) bool {
+	for _, validator := range genesis.Validators {
+		if strings.EqualFold(validator.PubKey.String(), pubKey.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJoinFiles writes the genesis file as it was fetched, the node key,
+// the config.toml (based on baseConfigFile or the default one) with the
+// moniker, chain ID and seeds set, and the default server_conf.toml
+// into the dir.
+func writeJoinFiles(dir, moniker, baseConfigFile string, genesis []byte, chainID string, seeds []string, priv map[string]interface{}) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "genesis.json"), genesis, 0600); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(dir, "priv_validator.json"), priv); err != nil {
+		return err
+	}
+
+	base := filepath.Join(ChainsPath, "default")
+	if baseConfigFile == "" {
+		baseConfigFile = filepath.Join(base, "config.toml")
+	}
+	baseConfig, err := ioutil.ReadFile(baseConfigFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	nodeConfig := setConfigValue(baseConfig, "moniker", moniker)
+	nodeConfig = setConfigValue(nodeConfig, "seeds", strings.Join(seeds, ","))
+	nodeConfig = configChainID.ReplaceAll(nodeConfig, []byte(fmt.Sprintf(`${1}"%s"`, chainID)))
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.toml"), nodeConfig, 0644); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(base, "server_conf.toml")); err != nil {
+		return nil
+	}
+	return Copy(filepath.Join(base, "server_conf.toml"), filepath.Join(dir, "server_conf.toml"))
+}
@@ -0,0 +1,545 @@
+package chains
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/rpc"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// PermissionFlags maps the account type permission names
+// to the chain permission bits.
+var PermissionFlags = map[string]uint64{
+	"root":            1 << 0,
+	"send":            1 << 1,
+	"call":            1 << 2,
+	"create_contract": 1 << 3,
+	"create_account":  1 << 4,
+	"bond":            1 << 5,
+	"name":            1 << 6,
+	"has_base":        1 << 7,
+	"set_base":        1 << 8,
+	"unset_base":      1 << 9,
+	"set_global":      1 << 10,
+	"has_role":        1 << 11,
+	"add_role":        1 << 12,
+	"rm_role":         1 << 13,
+}
+
+// Key generator, replaced in tests.
+var generateKey = generateValidatorKey
+
+// Answers to the [eris chains make] wizard questions, replaced in tests.
+var wizardInput io.Reader = os.Stdin
+
+// chainAccount is an account [eris chains make] puts into the genesis.
+type chainAccount struct {
+	Name    string
+	Address string
+	PubKey  rpc.PubKey
+	Tokens  int64
+	// validator bond (accounts with no bond aren't validators)
+	Bond int64
+	// permission bits and the bits of the permissions set explicitly
+	// (HasPerms is false if the permissions weren't given at all)
+	Perms    uint64
+	SetBit   uint64
+	HasPerms bool
+	// priv_validator.json contents, if the key was generated
+	Priv map[string]interface{}
+}
+
+// MakeChain creates the files needed to start a new chain in the
+// ~/.eris/chains/NAME directory:
+//
+//  genesis.json         - the genesis document
+//  config.toml          - chain config (based on ~/.eris/chains/default)
+//  priv_validator.json  - the key of the first validator, if keys are made
+//  accounts.csv         - PUBKEY,TOKENS,NAME,PERMS,SETBIT lines
+//  validators.csv       - PUBKEY,BOND,NAME,PERMS,SETBIT lines
+//  addresses.csv        - ADDRESS,NAME lines
+//  VALIDATOR/           - a directory with the genesis.json, config.toml,
+//                         and priv_validator.json of every validator
+//                         (VALIDATOR.tar.gz or VALIDATOR.zip if requested)
+//
+// The accounts and validators are made according to the account types
+// (see definitions.AccountType), with keys generated by the keys service.
+// With do.Known no keys are generated, and the genesis is made of the
+// known keys from the CSV files. If none of do.Known, do.AccountTypes,
+// or do.ChainType are set, MakeChain asks for the number of accounts of
+// every account type.
+//
+//  do.Name          - name of the chain to be created (required)
+//  do.ChainID       - chain ID (defaults to do.Name)
+//  do.Known         - make the genesis of the known keys from CSV files (requires do.ChainMakeVals and do.ChainMakeActs) (optional)
+//  do.ChainMakeVals - comma separated list of validators CSV files in the validators.csv format (optional)
+//  do.ChainMakeActs - comma separated list of accounts CSV files in the accounts.csv format (optional)
+//  do.AccountTypes  - numbers of accounts of every account type (example: Full:1,Participant:25,...) (optional)
+//  do.ChainType     - chain type (example: simplechain), overridden by do.AccountTypes (optional)
+//  do.Tarball       - package the validator directories into tarballs (optional)
+//  do.ZipFile       - package the validator directories into zip files (optional)
+//  do.Output        - display the accounts made (optional)
+//
+func MakeChain(do *definitions.Do) error {
+	chainID := do.ChainID
+	if chainID == "" {
+		chainID = do.Name
+	}
+
+	var (
+		accounts []*chainAccount
+		err      error
+	)
+	if do.Known {
+		accounts, err = knownAccounts(do.ChainMakeVals, do.ChainMakeActs)
+	} else {
+		accounts, err = typedAccounts(do)
+	}
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("No accounts to make the chain of")
+	}
+
+	if !do.Known {
+		for _, account := range accounts {
+			log.WithField("=>", account.Name).Info("Generating key")
+			address, priv, err := generateKey()
+			if err != nil {
+				return err
+			}
+			pubKey, err := json.Marshal(priv["pub_key"])
+			if err != nil {
+				return err
+			}
+			account.Address, account.PubKey, account.Priv = address, rpc.PubKey(pubKey), priv
+		}
+	}
+
+	dir := filepath.Join(ChainsPath, do.Name)
+	log.WithFields(log.Fields{
+		"=>":       do.Name,
+		"chain id": chainID,
+		"accounts": len(accounts),
+		"dir":      dir,
+	}).Warn("Making chain")
+	if err := writeChainFiles(dir, chainID, accounts, do.Tarball, do.ZipFile); err != nil {
+		return err
+	}
+
+	if do.Output {
+		if err := printAccounts(accounts); err != nil {
+			return err
+		}
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// typedAccounts returns the accounts to make according to do.AccountTypes,
+// do.ChainType, or the wizard answers.
+func typedAccounts(do *definitions.Do) ([]*chainAccount, error) {
+	types, err := loaders.LoadAccountTypes()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("No account types found in %s", AccountsTypePath)
+	}
+
+	var counts map[string]int
+	switch {
+	case len(do.AccountTypes) > 0:
+		if counts, err = accountTypeCounts(do.AccountTypes); err != nil {
+			return nil, err
+		}
+	case do.ChainType != "":
+		chainType, err := loaders.LoadChainType(do.ChainType)
+		if err != nil {
+			return nil, err
+		}
+		counts = chainType.AccountTypes
+	default:
+		if counts, err = askAccountTypeCounts(types); err != nil {
+			return nil, err
+		}
+	}
+
+	return makeAccounts(do.Name, types, counts)
+}
+
+// accountTypeCounts parses the NAME:NUMBER pairs.
+func accountTypeCounts(pairs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid account type %q. Please use the NAME:NUMBER format, e.g. Full:1", pair)
+		}
+		n, err := strconv.Atoi(pair[i+1:])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid number of accounts in %q", pair)
+		}
+		counts[pair[:i]] = n
+	}
+	return counts, nil
+}
+
+// askAccountTypeCounts asks for the number of accounts of every type.
+func askAccountTypeCounts(types []*definitions.AccountType) (map[string]int, error) {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(wizardInput)
+	for _, accountType := range types {
+		fmt.Fprintf(config.GlobalConfig.Writer, "\n%s accounts: %s\n", accountType.Name, strings.TrimSpace(accountType.Definition))
+		fmt.Fprintf(config.GlobalConfig.Writer, "How many %s accounts would you like? (default: %d) ", accountType.Name, accountType.DefaultNumber)
+
+		counts[accountType.Name] = accountType.DefaultNumber
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		if answer == "" {
+			continue
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Invalid number of %s accounts %q", accountType.Name, answer)
+		}
+		counts[accountType.Name] = n
+	}
+	fmt.Fprintln(config.GlobalConfig.Writer)
+	return counts, nil
+}
+
+// makeAccounts returns the counts of accounts of the account types
+// named CHAIN_TYPE_000, CHAIN_TYPE_001, etc., in the order of types.
+func makeAccounts(chain string, types []*definitions.AccountType, counts map[string]int) ([]*chainAccount, error) {
+	byName := make(map[string]*definitions.AccountType)
+	for _, accountType := range types {
+		byName[strings.ToLower(accountType.Name)] = accountType
+	}
+	for name := range counts {
+		if _, ok := byName[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("Unknown account type %q. Please see the account types in %s", name, AccountsTypePath)
+		}
+	}
+
+	var accounts []*chainAccount
+	for _, accountType := range types {
+		var n int
+		for name, count := range counts {
+			if strings.EqualFold(name, accountType.Name) {
+				n = count
+			}
+		}
+
+		perms, setBit, err := permissionBits(accountType.Perms)
+		if err != nil {
+			return nil, fmt.Errorf("Account type %s: %v", accountType.Name, err)
+		}
+
+		for i := 0; i < n; i++ {
+			accounts = append(accounts, &chainAccount{
+				Name:     fmt.Sprintf("%s_%s_%03d", chain, strings.ToLower(accountType.Name), i),
+				Tokens:   accountType.DefaultTokens,
+				Bond:     accountType.DefaultBond,
+				Perms:    perms,
+				SetBit:   setBit,
+				HasPerms: len(accountType.Perms) > 0,
+			})
+		}
+	}
+	return accounts, nil
+}
+
+// permissionBits converts the permission names to the bits
+// of the permissions granted and set.
+func permissionBits(perms map[string]int) (granted, set uint64, err error) {
+	for name, value := range perms {
+		flag, ok := PermissionFlags[strings.ToLower(name)]
+		if !ok {
+			return 0, 0, fmt.Errorf("unknown permission %q", name)
+		}
+		set |= flag
+		if value != 0 {
+			granted |= flag
+		}
+	}
+	return granted, set, nil
+}
+
+// knownAccounts reads the validators and the accounts from
+// the comma separated lists of CSV files.
+func knownAccounts(validatorFiles, accountFiles string) ([]*chainAccount, error) {
+	var accounts []*chainAccount
+	for _, files := range []struct {
+		list       string
+		validators bool
+	}{
+		{validatorFiles, true},
+		{accountFiles, false},
+	} {
+		for _, file := range strings.Split(files.list, ",") {
+			if file == "" {
+				continue
+			}
+			more, err := readAccountsCSV(file, files.validators)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, more...)
+		}
+	}
+	return accounts, nil
+}
+
+// readAccountsCSV reads PUBKEY,AMOUNT[,NAME[,PERMS[,SETBIT]]] lines from
+// the file. The amount is the validator bond if validators is true, or
+// the account balance otherwise.
+func readAccountsCSV(fileName string, validators bool) ([]*chainAccount, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read %s: %v", fileName, err)
+	}
+
+	var accounts []*chainAccount
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected at least the PUBKEY,AMOUNT fields", fileName, i+1)
+		}
+
+		address, err := pubKeyAddress(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fileName, i+1, err)
+		}
+		amount, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid amount %q", fileName, i+1, record[1])
+		}
+
+		account := &chainAccount{
+			Address: address,
+			PubKey:  rpc.PubKey(fmt.Sprintf(`[1,"%s"]`, strings.ToUpper(record[0]))),
+		}
+		if validators {
+			account.Bond = amount
+		} else {
+			account.Tokens = amount
+		}
+		if len(record) > 2 {
+			account.Name = record[2]
+		}
+		if len(record) > 4 {
+			if account.Perms, err = strconv.ParseUint(record[3], 10, 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid permissions %q", fileName, i+1, record[3])
+			}
+			if account.SetBit, err = strconv.ParseUint(record[4], 10, 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid permissions set bits %q", fileName, i+1, record[4])
+			}
+			account.HasPerms = true
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// pubKeyAddress returns the chain address of the hex encoded
+// ed25519 public key.
+func pubKeyAddress(pubKey string) (string, error) {
+	key, err := hex.DecodeString(pubKey)
+	if err != nil || len(key) != 32 {
+		return "", fmt.Errorf("invalid public key %q: expected 64 hex digits", pubKey)
+	}
+
+	// The key type (1 for ed25519) and
+	// the length prefixed key bytes.
+	encoded := append([]byte{1, 1, byte(len(key))}, key...)
+	return strings.ToUpper(hex.EncodeToString(ripemd160(encoded))), nil
+}
+
+// makeGenesis returns the genesis document listing the accounts.
+// Accounts with a bond are validators too.
+func makeGenesis(chainID string, accounts []*chainAccount) *rpc.Genesis {
+	genesis := &rpc.Genesis{
+		GenesisTime: time.Now().UTC().Format(time.RFC3339),
+		ChainID:     chainID,
+		Accounts:    []*rpc.GenesisAccount{},
+		Validators:  []*rpc.GenesisValidator{},
+	}
+
+	for _, account := range accounts {
+		if account.Tokens > 0 || account.Bond == 0 {
+			entry := &rpc.GenesisAccount{
+				Address: account.Address,
+				Amount:  account.Tokens,
+				Name:    account.Name,
+			}
+			if account.HasPerms {
+				entry.Permissions = json.RawMessage(fmt.Sprintf(`{"base":{"perms":%d,"set_bit":%d},"roles":[]}`, account.Perms, account.SetBit))
+			}
+			genesis.Accounts = append(genesis.Accounts, entry)
+		}
+
+		if account.Bond > 0 {
+			genesis.Validators = append(genesis.Validators, &rpc.GenesisValidator{
+				PubKey: account.PubKey,
+				Amount: account.Bond,
+				Name:   account.Name,
+				UnbondTo: []*rpc.GenesisAccount{{
+					Address: account.Address,
+					Amount:  account.Bond,
+				}},
+			})
+		}
+	}
+	return genesis
+}
+
+// writeChainFiles writes the chain files (see MakeChain) into the dir.
+func writeChainFiles(dir, chainID string, accounts []*chainAccount, tarball, zipFile bool) error {
+	genesis := makeGenesis(chainID, accounts)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := writeNodeFiles(dir, dir, chainID, genesis, nil); err != nil {
+		return err
+	}
+
+	var (
+		accountsCSV, validatorsCSV, addressesCSV []string
+		first                                    = true
+	)
+	for _, account := range accounts {
+		line := fmt.Sprintf("%s,%%d,%s,%d,%d", account.PubKey.String(), account.Name, account.Perms, account.SetBit)
+		accountsCSV = append(accountsCSV, fmt.Sprintf(line, account.Tokens))
+		addressesCSV = append(addressesCSV, account.Address+","+account.Name)
+
+		if account.Bond == 0 {
+			continue
+		}
+		validatorsCSV = append(validatorsCSV, fmt.Sprintf(line, account.Bond))
+
+		if account.Priv == nil {
+			continue
+		}
+		// The chain directory itself starts the first validator.
+		if first {
+			if err := writeJSON(filepath.Join(dir, "priv_validator.json"), account.Priv); err != nil {
+				return err
+			}
+			first = false
+		}
+
+		nodeDir := filepath.Join(dir, account.Name)
+		if err := os.MkdirAll(nodeDir, 0700); err != nil {
+			return err
+		}
+		if err := writeNodeFiles(nodeDir, account.Name, chainID, genesis, account.Priv); err != nil {
+			return err
+		}
+		if err := packNodeDir(nodeDir, tarball, zipFile); err != nil {
+			return err
+		}
+	}
+
+	for file, lines := range map[string][]string{
+		"accounts.csv":   accountsCSV,
+		"validators.csv": validatorsCSV,
+		"addresses.csv":  addressesCSV,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNodeFiles writes the genesis.json, config.toml (with the moniker
+// and chain ID set), server_conf.toml and, unless priv is nil, the
+// priv_validator.json file of a chain node into the dir.
+func writeNodeFiles(dir, moniker, chainID string, genesis *rpc.Genesis, priv map[string]interface{}) error {
+	if err := writeJSON(filepath.Join(dir, "genesis.json"), genesis); err != nil {
+		return err
+	}
+	if priv != nil {
+		if err := writeJSON(filepath.Join(dir, "priv_validator.json"), priv); err != nil {
+			return err
+		}
+	}
+
+	base := filepath.Join(ChainsPath, "default")
+	baseConfig, err := ioutil.ReadFile(filepath.Join(base, "config.toml"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	nodeConfig := setConfigValue(baseConfig, "moniker", filepath.Base(moniker))
+	nodeConfig = configChainID.ReplaceAll(nodeConfig, []byte(fmt.Sprintf(`${1}"%s"`, chainID)))
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.toml"), nodeConfig, 0644); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(base, "server_conf.toml")); err != nil {
+		return nil
+	}
+	return Copy(filepath.Join(base, "server_conf.toml"), filepath.Join(dir, "server_conf.toml"))
+}
+
+// packNodeDir replaces the node directory with a tarball
+// or a zip file, if requested.
+func packNodeDir(dir string, tarball, zipFile bool) error {
+	switch {
+	case tarball:
+		if _, err := util.PackTarball(dir, dir+".tar.gz"); err != nil {
+			return err
+		}
+	case zipFile:
+		if _, err := util.PackZip(dir, dir+".zip"); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
+
+func printAccounts(accounts []*chainAccount) error {
+	tw := tabwriter.NewWriter(config.GlobalConfig.Writer, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tADDRESS\tTOKENS\tBOND")
+	for _, account := range accounts {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", account.Name, account.Address, account.Tokens, account.Bond)
+	}
+	return tw.Flush()
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/data"
@@ -16,147 +17,27 @@ import (
 	"github.com/eris-ltd/eris-cli/perform"
 	"github.com/eris-ltd/eris-cli/services"
 	"github.com/eris-ltd/eris-cli/util"
-	"github.com/eris-ltd/eris-cli/version"
 
 	. "github.com/eris-ltd/common/go/common"
 	"github.com/eris-ltd/common/go/ipfs"
 	log "github.com/eris-ltd/eris-logger"
 )
 
-// MakeChain runs the `eris-cm make` command in a docker container.
-// It returns an error. Note that if do.Known, do.AccountTypes
-// or do.ChainType are not set the command will run via interactive
-// shell.
+// ImportChain fetches the chain definition file from a location
+// (see loaders.Resolvers), validates it and writes it into ChainsPath.
 //
-//  do.Name          - name of the chain to be created (required)
-//  do.Known         - will use the mintgen tool to parse csv's and create a genesis.json (requires do.ChainMakeVals and do.ChainMakeActs) (optional)
-//  do.ChainMakeVals - csv file to use for validators (optional)
-//  do.ChainMakeActs - csv file to use for accounts (optional)
-//  do.AccountTypes  - use eris-cm make account-types paradigm (example: Root:1,Participants:25,...) (optional)
-//  do.ChainType     - use eris-cm make chain-types paradigm (example: simplechain) (optional)
-//  do.Tarball       - instead of outputing raw files in directories, output packages of tarbals (optional)
-//  do.ZipFile       - similar to do.Tarball except uses zipfiles (optional)
-//  do.Verbose       - verbose output (optional)
-//  do.Debug         - debug output (optional)
+//  do.Name - name of the chain to import (required)
+//  do.Path - location of the definition file (required)
 //
-func MakeChain(do *definitions.Do) error {
-	if err := checkKeysRunningOrStart(); err != nil {
-		return err
-	}
-
-	do.Service.Name = do.Name
-	do.Service.Image = path.Join(version.ERIS_REG_DEF, version.ERIS_IMG_CM)
-	do.Service.User = "eris"
-	do.Service.AutoData = true
-	do.Service.Links = []string{fmt.Sprintf("%s:%s", util.ServiceContainerName("keys"), "keys")}
-	do.Service.Environment = []string{
-		fmt.Sprintf("ERIS_KEYS_PATH=http://keys:%d", 4767), // note, needs to be made aware of keys port...
-		fmt.Sprintf("ERIS_CHAINMANAGER_ACCOUNTTYPES=%s", strings.Join(do.AccountTypes, ",")),
-		fmt.Sprintf("ERIS_CHAINMANAGER_CHAINTYPE=%s", do.ChainType),
-		fmt.Sprintf("ERIS_CHAINMANAGER_TARBALLS=%v", do.Tarball),
-		fmt.Sprintf("ERIS_CHAINMANAGER_ZIPFILES=%v", do.ZipFile),
-		fmt.Sprintf("ERIS_CHAINMANAGER_OUTPUT=%v", do.Output),
-		fmt.Sprintf("ERIS_CHAINMANAGER_VERBOSE=%v", do.Verbose),
-		fmt.Sprintf("ERIS_CHAINMANAGER_DEBUG=%v", do.Debug),
-	}
-
-	do.Operations.ContainerType = definitions.TypeService
-	do.Operations.SrvContainerName = util.ServiceContainerName(do.Name)
-	do.Operations.DataContainerName = util.DataContainerName(do.Name)
-	do.Operations.Labels = util.Labels(do.Name, do.Operations)
-	if do.RmD {
-		do.Operations.Remove = true
-	}
-
-	if do.Known {
-		log.Debug("Using MintGen rather than eris:cm")
-		do.Service.EntryPoint = "mintgen"
-		do.Service.Command = fmt.Sprintf("known %s --csv=%s,%s > %s", do.Name, do.ChainMakeVals, do.ChainMakeActs, path.Join(ErisContainerRoot, "chains", do.Name, "genesis.json"))
-	} else {
-		log.Debug("Using eris:cm rather than MintGen")
-		do.Service.EntryPoint = fmt.Sprintf("eris-cm make %s", do.Name)
-	}
-
-	if !do.Known && len(do.AccountTypes) == 0 && do.ChainType == "" {
-		do.Operations.Interactive = true
-		do.Operations.Args = strings.Split(do.Service.EntryPoint, " ")
-	}
-
-	if do.Known {
-		do.Operations.Args = append(do.Operations.Args, strings.Split(do.Service.Command, " ")...)
-		do.Service.WorkDir = path.Join(ErisContainerRoot, "chains", do.Name)
-	}
-
-	doData := definitions.NowDo()
-	doData.Name = do.Name
-
-	doData.Operations.DataContainerName = util.DataContainerName(do.Name)
-	doData.Operations.ContainerType = "service"
-
-	doData.Source = AccountsTypePath
-	doData.Destination = path.Join(ErisContainerRoot, "chains", "account-types")
-	if err := data.ImportData(doData); err != nil {
-		return err
-	}
-
-	doData.Source = ChainTypePath
-	doData.Destination = path.Join(ErisContainerRoot, "chains", "chain-types")
-	if err := data.ImportData(doData); err != nil {
-		return err
-	}
-
-	chnPath := filepath.Join(ChainsPath, do.Name)
-	doData.Source = chnPath
-	doData.Destination = path.Join(ErisContainerRoot, "chains", do.Name)
-	if err := data.ImportData(doData); err != nil {
-		return err
-	}
-
-	buf, err := perform.DockerExecService(do.Service, do.Operations)
-	if err != nil {
-		return err
-	}
-
-	io.Copy(config.GlobalConfig.Writer, buf)
-
-	doData.Source = path.Join(ErisContainerRoot, "chains")
-	doData.Destination = ErisRoot
-	if err := data.ExportData(doData); err != nil {
+func ImportChain(do *definitions.Do) error {
+	if _, err := loaders.ImportDefinition("chains", do.Name, do.Path); err != nil {
 		return err
 	}
 
-	if !do.RmD {
-		return data.RmData(doData)
-	}
-
+	do.Result = "success"
 	return nil
 }
 
-// ImportChain pulls a chain definition file from IPFS and saves
-// that file into ChainPath. It returns an error.
-//
-//  do.Name          - name of the chain to be imported (required)
-//  do.Path          - path to export to; currently only supports ipfs (example: ipfs:QmVdjShTMLAD6YTEgQ1wen1ym4p19ZWepCYTf1MNC1f1Ft) (required)
-//
-func ImportChain(do *definitions.Do) error {
-	fileName := filepath.Join(ChainsPath, do.Name)
-	if filepath.Ext(fileName) == "" {
-		fileName = fileName + ".toml"
-	}
-
-	s := strings.Split(do.Path, ":")
-	if s[0] == "ipfs" {
-		return ipfs.GetFromIPFS(s[1], fileName, "")
-	}
-
-	if strings.Contains(s[0], "github") {
-		log.Warn("https://twitter.com/ryaneshea/status/595957712040628224")
-		return nil
-	}
-
-	return fmt.Errorf("I do not know how to get that file. Sorry.")
-}
-
 // InspectChain is eris' version of docker inspect. It returns
 // an error.
 //
@@ -236,15 +117,41 @@ To find known chains use: eris chains ls --known`)
 // scoping function which is used by other portions of the
 // platform where a --chain flag may otherwise be used.
 //
-//  do.Name - the name of the chain to checkout; if blank will "uncheckout" current chain (optional)
+//  do.Name  - the name of the chain to checkout; if blank will "uncheckout" current chain;
+//             "-" checks out the previously checked out chain (optional)
+//  do.Local - write the name to the project-local .eris-chain file in the working
+//             directory instead; it overrides the ChainPath/HEAD file (optional)
 //
 func CheckoutChain(do *definitions.Do) error {
+	if do.Name == "-" {
+		previous, err := util.PreviousHead()
+		if err != nil {
+			return err
+		}
+		do.Name = previous
+	}
+
+	if do.Local {
+		if do.Name != "" && !util.IsKnownChain(do.Name) {
+			return fmt.Errorf("I cannot find the %s chain definition file. Please check the chain name you sent me", do.Name)
+		}
+		log.WithField("file", util.LocalHeadFile).Info("Writing project-local checkout")
+		return util.ChangeLocalHead(do.Name)
+	}
+
+	if local, _ := util.GetLocalHead(); local != "" {
+		log.WithFields(log.Fields{
+			"=>":   local,
+			"file": util.LocalHeadFile,
+		}).Warn("The project-local checkout overrides the checked out chain in this directory")
+	}
+
 	if do.Name == "" {
 		do.Result = "nil"
 		return util.NullHead()
 	}
 
-	curHead, _ := util.GetHead()
+	curHead, _ := util.GetGlobalHead()
 	if do.Name == curHead {
 		do.Result = "no change"
 		return nil
@@ -261,6 +168,8 @@ func CurrentChain(do *definitions.Do) error {
 
 	if head == "" {
 		head = "There is no chain checked out."
+	} else if local, _ := util.GetLocalHead(); local != "" {
+		log.WithField("file", util.LocalHeadFile).Info("Using project-local checkout")
 	}
 
 	log.Warn(head)
@@ -269,11 +178,38 @@ func CurrentChain(do *definitions.Do) error {
 	return nil
 }
 
+// HistoryChain displays the recently checked out chains (the latest
+// first) along with the checkout times. It returns the HEAD file
+// read errors.
+//
+func HistoryChain(do *definitions.Do) error {
+	history, err := util.HeadHistory()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(config.GlobalConfig.Writer, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tCHECKED OUT")
+	for _, entry := range history {
+		name, checkedOut := entry.Name, "-"
+		if name == "" {
+			name = "(none)"
+		}
+		if !entry.Time.IsZero() {
+			checkedOut = entry.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", name, checkedOut)
+	}
+	return tw.Flush()
+}
+
 // CatChain displays chain information. It returns nil on success, or input/output
 // errors otherwise.
 //
-//  do.Name - chain name
-//  do.Type - "toml", "genesis", "status", "validators", or "toml"
+//  do.Name   - chain name
+//  do.Type   - "toml", "config", "genesis", "status", "validators", or "peers"
+//  do.Format - output format of "status", "validators", and "peers":
+//              "json", a Go template, or "" for a table
 //
 func CatChain(do *definitions.Do) error {
 	rootDir := path.Join(ErisContainerRoot, "chains", do.Name)
@@ -283,11 +219,17 @@ func CatChain(do *definitions.Do) error {
 	case "config":
 		do.Operations.Args = []string{"cat", path.Join(rootDir, "config.toml")}
 	case "status":
-		do.Operations.Args = []string{"mintinfo", "--node-addr", "http://chain:46657", "status"}
+		return catStatus(do)
 	case "validators":
-		do.Operations.Args = []string{"mintinfo", "--node-addr", "http://chain:46657", "validators"}
+		return catValidators(do)
+	case "peers":
+		return catPeers(do)
 	case "toml":
-		cat, err := ioutil.ReadFile(filepath.Join(ChainsPath, do.Name+".toml"))
+		file := util.GetFileByNameAndType("chains", do.Name)
+		if file == "" {
+			file = filepath.Join(ChainsPath, do.Name+".toml")
+		}
+		cat, err := ioutil.ReadFile(file)
 		if err != nil {
 			return err
 		}
@@ -308,7 +250,7 @@ func CatChain(do *definitions.Do) error {
 	return err
 }
 
-// PortsChain displays the port mapping for a particular chain.
+// PortsChain displays the endpoints and port mappings for a particular chain.
 // It returns an error.
 //
 //  do.Name - name of the chain to display port mappings for (required)
@@ -320,8 +262,8 @@ func PortsChain(do *definitions.Do) error {
 	}
 
 	if util.IsChain(chain.Name, false) {
-		log.WithField("=>", chain.Name).Debug("Getting chain port mapping")
-		return util.PrintPortMappings(chain.Operations.SrvContainerName, do.Operations.Args)
+		log.WithField("=>", chain.Name).Debug("Getting chain endpoints")
+		return util.PrintEndpoints(chain.Service, chain.Operations, do.Operations.Args)
 	}
 
 	return nil
@@ -356,15 +298,17 @@ func RenameChain(do *definitions.Do) error {
 			"to":   do.NewName,
 		}).Info("Renaming chain")
 
+		// The definition file is rewritten as is, without
+		// resolving its variables, extends, and overlays.
 		log.WithField("=>", do.Name).Debug("Loading chain definition file")
-		chainDef, err := loaders.LoadChainDefinition(do.Name)
+		definition, err := config.LoadViperConfig(ChainsPath, do.Name)
 		if err != nil {
 			return err
 		}
 
 		if !transformOnly {
 			log.Debug("Renaming chain container")
-			err = perform.DockerRename(chainDef.Operations, do.NewName)
+			err = perform.DockerRename(loaders.MockChainDefinition(do.Name, "").Operations, do.NewName)
 			if err != nil {
 				return err
 			}
@@ -388,13 +332,7 @@ func RenameChain(do *definitions.Do) error {
 			newFile = filepath.Join(ChainsPath, do.NewName)
 		}
 
-		chainDef.Name = newNameBase
-		// Generally we won't want to use Service.Name
-		// as it will be confused with the Name.
-		chainDef.Service.Name = ""
-		// Service.Image should be taken from the default.toml.
-		chainDef.Service.Image = ""
-		err = WriteChainDefinitionFile(chainDef, newFile)
+		err = config.RenameDefinitionFile(definition, newNameBase, newFile)
 		if err != nil {
 			return err
 		}
@@ -439,6 +377,10 @@ func UpdateChain(do *definitions.Do) error {
 }
 
 func RemoveChain(do *definitions.Do) error {
+	if isCluster(do.Name) {
+		return removeCluster(do)
+	}
+
 	chain, err := loaders.LoadChainDefinition(do.Name)
 	if err != nil {
 		return err
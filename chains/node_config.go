@@ -0,0 +1,309 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/data"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigKey describes the value of a known config.toml key.
+type ConfigKey struct {
+	// "string", "bool", "int", "address" (HOST:PORT, optionally
+	// prefixed with tcp://), "addresses" (comma separated), or "enum"
+	Kind string
+	// allowed values of the "enum" kind
+	Values []string
+}
+
+// ConfigKeys maps the known top level keys of the chain node
+// config.toml file to their value types.
+var ConfigKeys = map[string]ConfigKey{
+	"moniker":             {Kind: "string"},
+	"seeds":               {Kind: "addresses"},
+	"fast_sync":           {Kind: "bool"},
+	"skip_upnp":           {Kind: "bool"},
+	"log_level":           {Kind: "enum", Values: []string{"debug", "info", "notice", "warn", "error"}},
+	"db_backend":          {Kind: "enum", Values: []string{"leveldb", "memdb"}},
+	"node_laddr":          {Kind: "address"},
+	"rpc_laddr":           {Kind: "address"},
+	"proxy_app":           {Kind: "address"},
+	"db_dir":              {Kind: "string"},
+	"cswal":               {Kind: "string"},
+	"genesis_file":        {Kind: "string"},
+	"priv_validator_file": {Kind: "string"},
+	"addrbook_file":       {Kind: "string"},
+	"timeout_propose":     {Kind: "int"},
+	"timeout_commit":      {Kind: "int"},
+}
+
+// ConfigChain displays or changes the config.toml file of the chain
+// node in its data container. After the file is changed, a running
+// chain is restarted if the user agrees (see UpdateChain).
+//
+//  do.Name            - name of the chain (required)
+//  do.Type            - "get", "set", or "edit" (required)
+//  do.Operations.Args - keys to display with "get" (the whole file is displayed if none)
+//                       or KEY=VALUE pairs to change with "set"
+//  do.Force           - allow the keys not listed in ConfigKeys to be set (optional)
+//  do.Yes             - restart the running chain without asking (optional)
+//
+func ConfigChain(do *definitions.Do) error {
+	chain, err := loaders.LoadChainDefinition(do.Name)
+	if err != nil {
+		return err
+	}
+	if !util.IsData(do.Name) {
+		return fmt.Errorf("The %s chain has no data container. Please create the chain with [eris chains new] first", do.Name)
+	}
+	chainID := chain.ChainID
+	if chainID == "" {
+		chainID = do.Name
+	}
+
+	conf, err := readNodeConfig(do.Name, chainID)
+	if err != nil {
+		return err
+	}
+
+	switch do.Type {
+	case "get":
+		return printNodeConfig(conf, do.Operations.Args)
+	case "set":
+		if len(do.Operations.Args) == 0 {
+			return fmt.Errorf("Please give the KEY=VALUE pairs to set")
+		}
+		for _, pair := range do.Operations.Args {
+			i := strings.Index(pair, "=")
+			if i <= 0 {
+				return fmt.Errorf("Config values should be KEY=VALUE pairs, got %q", pair)
+			}
+			raw, err := configValue(pair[:i], pair[i+1:], do.Force)
+			if err != nil {
+				return err
+			}
+			conf = setConfigRaw(conf, pair[:i], raw)
+		}
+	case "edit":
+		if conf, err = editNodeConfig(conf); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown config subcommand %q. Please use get, set, or edit", do.Type)
+	}
+
+	if err := checkNodeConfig(conf); err != nil {
+		return err
+	}
+	if err := writeNodeConfig(do.Name, chainID, conf); err != nil {
+		return err
+	}
+	do.Result = "success"
+
+	if !util.IsChain(chain.Name, true) {
+		return nil
+	}
+	if !do.Yes && QueryYesOrNo("The chain is running. Would you like to restart it to apply the changes?") != Yes {
+		log.WithField("=>", do.Name).Warn("The changes take effect after [eris chains restart]")
+		return nil
+	}
+	restart := definitions.NowDo()
+	restart.Name = do.Name
+	restart.Timeout = do.Timeout
+	return UpdateChain(restart)
+}
+
+// configValue checks the value against the known key type and
+// returns its TOML representation. Unknown keys are strings and
+// are only allowed if force is true.
+func configValue(key, value string, force bool) (string, error) {
+	known, ok := ConfigKeys[key]
+	if !ok {
+		if !force {
+			return "", fmt.Errorf("Unknown config key %q. The known keys are %s. Please use the --force flag to set it anyway", key, strings.Join(knownConfigKeys(), ", "))
+		}
+		known.Kind = "string"
+	}
+
+	switch known.Kind {
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("The %s config value should be true or false, got %q", key, value)
+		}
+		return strconv.FormatBool(b), nil
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("The %s config value should be a number, got %q", key, value)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "enum":
+		for _, allowed := range known.Values {
+			if value == allowed {
+				return strconv.Quote(value), nil
+			}
+		}
+		return "", fmt.Errorf("The %s config value should be one of %s, got %q", key, strings.Join(known.Values, ", "), value)
+	case "address":
+		if err := checkConfigAddress(value); err != nil {
+			return "", fmt.Errorf("The %s config value %v", key, err)
+		}
+	case "addresses":
+		for _, address := range strings.Split(value, ",") {
+			if address == "" {
+				continue
+			}
+			if err := checkConfigAddress(address); err != nil {
+				return "", fmt.Errorf("The %s config value %v", key, err)
+			}
+		}
+	}
+	return strconv.Quote(value), nil
+}
+
+func checkConfigAddress(address string) error {
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(address, "tcp://"))
+	if err != nil {
+		return fmt.Errorf("should be a HOST:PORT address, got %q", address)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("has an invalid port in %q", address)
+	}
+	return nil
+}
+
+// checkNodeConfig checks that the config is a valid TOML document
+// and that the known keys have values of the right types.
+func checkNodeConfig(conf []byte) error {
+	values := make(map[string]interface{})
+	if _, err := toml.Decode(string(conf), &values); err != nil {
+		return fmt.Errorf("The config is not a valid TOML document: %v", err)
+	}
+
+	for key, known := range ConfigKeys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch v := value.(type) {
+		case string:
+			_, err = configValue(key, v, false)
+		case bool:
+			if known.Kind != "bool" {
+				err = fmt.Errorf("The %s config value should be a %s, got %v", key, known.Kind, v)
+			}
+		case int64:
+			if known.Kind != "int" {
+				err = fmt.Errorf("The %s config value should be a %s, got %v", key, known.Kind, v)
+			}
+		default:
+			err = fmt.Errorf("The %s config value should be a %s, got %v", key, known.Kind, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printNodeConfig(conf []byte, keys []string) error {
+	if len(keys) == 0 {
+		config.GlobalConfig.Writer.Write(conf)
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	if _, err := toml.Decode(string(conf), &values); err != nil {
+		return fmt.Errorf("The config is not a valid TOML document: %v", err)
+	}
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			return fmt.Errorf("The config has no %q key", key)
+		}
+		if len(keys) == 1 {
+			fmt.Fprintln(config.GlobalConfig.Writer, value)
+		} else {
+			fmt.Fprintf(config.GlobalConfig.Writer, "%s = %v\n", key, value)
+		}
+	}
+	return nil
+}
+
+// editNodeConfig opens the config in the default editor
+// and returns the edited config.
+func editNodeConfig(conf []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "eris_config_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(file, conf, 0644); err != nil {
+		return nil, err
+	}
+	if err := Editor(file); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(file)
+}
+
+func readNodeConfig(name, chainID string) ([]byte, error) {
+	do := definitions.NowDo()
+	do.Name = name
+	do.Operations.Args = []string{"cat", path.Join(ErisContainerRoot, "chains", chainID, "config.toml")}
+	buf, err := data.ExecData(do)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the config.toml file of the %s chain: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeNodeConfig(name, chainID string, conf []byte) error {
+	dir, err := ioutil.TempDir("", "eris_config_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.toml"), conf, 0644); err != nil {
+		return err
+	}
+
+	log.WithField("=>", name).Warn("Writing config.toml")
+	do := definitions.NowDo()
+	do.Name = name
+	do.Source = dir
+	do.Destination = path.Join(ErisContainerRoot, "chains", chainID)
+	return data.ImportData(do)
+}
+
+// knownConfigKeys returns the sorted ConfigKeys names.
+func knownConfigKeys() []string {
+	var keys []string
+	for key := range ConfigKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
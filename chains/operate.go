@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/data"
@@ -23,7 +24,21 @@ import (
 	"github.com/pborman/uuid"
 )
 
+// DefaultWaitTimeout is the number of seconds to wait
+// for a chain to produce blocks (see WaitChain).
+const DefaultWaitTimeout = 60
+
 func NewChain(do *definitions.Do) error {
+	if do.N > 1 {
+		if err := newCluster(do); err != nil {
+			return err
+		}
+		if do.Wait {
+			return waitCluster(do)
+		}
+		return nil
+	}
+
 	dir := filepath.Join(DataContainersPath, do.Name)
 	if util.DoesDirExist(dir) {
 		log.WithField("dir", dir).Debug("Chain data already exists in")
@@ -36,7 +51,14 @@ func NewChain(do *definitions.Do) error {
 	// for now we just let setupChain force do.ChainID = do.Name
 	// and we overwrite using jq in the container
 	log.WithField("=>", do.Name).Debug("Setting up chain")
-	return setupChain(do, loaders.ErisChainNew)
+	if err := setupChain(do, loaders.ErisChainNew); err != nil {
+		return err
+	}
+
+	if do.Wait {
+		return WaitChain(do)
+	}
+	return nil
 }
 
 func InstallChain(do *definitions.Do) error {
@@ -44,6 +66,10 @@ func InstallChain(do *definitions.Do) error {
 }
 
 func KillChain(do *definitions.Do) error {
+	if isCluster(do.Name) {
+		return killCluster(do)
+	}
+
 	chain, err := loaders.LoadChainDefinition(do.Name)
 	if err != nil {
 		return err
@@ -72,8 +98,33 @@ func KillChain(do *definitions.Do) error {
 
 func StartChain(do *definitions.Do) error {
 	_, err := startChain(do, false)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if do.Wait && do.Result == "" {
+		return WaitChain(do)
+	}
+	return nil
+}
+
+// WaitChain waits until the running chain answers RPC status requests
+// and produces blocks (see perform.DockerWaitChainReady).
+//
+//  do.Name        - name of the chain (required)
+//  do.WaitTimeout - seconds to wait (defaults to DefaultWaitTimeout)
+//
+func WaitChain(do *definitions.Do) error {
+	chain, err := loaders.LoadChainDefinition(do.Name)
+	if err != nil {
+		return err
+	}
+
+	timeout := do.WaitTimeout
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+	return perform.DockerWaitChainReady(chain.Service, chain.Operations, time.Duration(timeout)*time.Second)
 }
 
 func ExecChain(do *definitions.Do) (buf *bytes.Buffer, err error) {
@@ -97,7 +148,7 @@ func ThrowAwayChain(do *definitions.Do) error {
 	do.Run = true  // turns on edb api
 	StartChain(do) // XXX [csk]: may not need to do this now that New starts....
 	log.WithField("=>", do.Name).Debug("Throwaway chain started")
-	return nil
+	return WaitChain(do)
 }
 
 func startChain(do *definitions.Do, exec bool) (buf *bytes.Buffer, err error) {
@@ -206,6 +257,10 @@ func bootDependencies(chain *definitions.Chain, do *definitions.Do) error {
 				}
 			}
 
+			// Don't start the chain before its dependencies are ready.
+			if err := perform.DockerWaitHealthy(srv.Service, srv.Operations); err != nil {
+				return err
+			}
 		}
 		do.Name = name // undo side effects
 
@@ -217,6 +272,9 @@ func bootDependencies(chain *definitions.Chain, do *definitions.Do) error {
 			if !util.IsChain(chn.Name, true) {
 				return fmt.Errorf("chain %s depends on chain %s but %s is not running", chain.Name, chainName, chainName)
 			}
+			if err := perform.DockerWaitHealthy(chn.Service, chn.Operations); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -333,6 +391,7 @@ func setupChain(do *definitions.Do, cmd string) (err error) {
 	log.WithField("image", chain.Service.Image).Debug("Chain loaded")
 	chain.Operations.PublishAllPorts = do.Operations.PublishAllPorts // TODO: remove this and marshall into struct from cli directly
 	chain.Operations.Ports = do.Operations.Ports
+	chain.Operations.AutoPorts = do.Operations.AutoPorts
 
 	// Cmd should be "new" or "install".
 	chain.Service.Command = cmd
@@ -377,7 +436,7 @@ func setupChain(do *definitions.Do, cmd string) (err error) {
 	log.Info("Moving priv_validator.json into eris-keys")
 	doKeys := definitions.NowDo()
 	doKeys.Name = do.Name
-	doKeys.Operations.Args = []string{"mintkey", "eris", fmt.Sprintf("%s/chains/%s/priv_validator.json", ErisContainerRoot, do.Name)}
+	doKeys.Operations.Args = []string{"mintkey", "eris", fmt.Sprintf("%s/chains/%s/priv_validator.json", ErisContainerRoot, do.ChainID)}
 	doKeys.Operations.SkipLink = true
 	if out, err := ExecChain(doKeys); err != nil {
 		if out != nil {
@@ -0,0 +1,68 @@
+package chains
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/eris-ltd/eris-cli/definitions"
+)
+
+// QueryChain asks the running chain about a block, a transaction,
+// an account, or a contract storage value and displays the answer.
+// QueryChain returns RPC or argument errors.
+//
+//  do.Name            - name of the chain (required)
+//  do.Type            - "block", "tx", "account", or "storage" (required)
+//  do.Operations.Args - block HEIGHT, tx HASH, account ADDRESS,
+//                       or storage ADDRESS KEY
+//  do.Format          - "json" (default) or a Go template
+//
+func QueryChain(do *definitions.Do) error {
+	queries := map[string]struct {
+		usage string
+		args  int
+	}{
+		"block":   {"block HEIGHT", 1},
+		"tx":      {"tx HASH", 1},
+		"account": {"account ADDRESS", 1},
+		"storage": {"storage ADDRESS KEY", 2},
+	}
+	query, ok := queries[do.Type]
+	if !ok {
+		return fmt.Errorf("Unknown query %q. Expecting block, tx, account, or storage", do.Type)
+	}
+	args := do.Operations.Args
+	if len(args) != query.args {
+		return fmt.Errorf("Expecting [eris chains query %s %s]", do.Name, query.usage)
+	}
+
+	client, err := ChainClient(do.Name)
+	if err != nil {
+		return err
+	}
+
+	var result interface{}
+	switch do.Type {
+	case "block":
+		height, errAtoi := strconv.Atoi(args[0])
+		if errAtoi != nil || height <= 0 {
+			return fmt.Errorf("Invalid block height %q: expecting a positive number", args[0])
+		}
+		result, err = client.Block(height)
+	case "tx":
+		result, err = client.Tx(args[0])
+	case "account":
+		result, err = client.Account(args[0])
+	case "storage":
+		result, err = client.Storage(args[0], args[1])
+	}
+	if err != nil {
+		return err
+	}
+
+	format := do.Format
+	if format == "" {
+		format = "json"
+	}
+	return printFormatted(result, format, false)
+}
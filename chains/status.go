@@ -0,0 +1,182 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/rpc"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// ChainClient returns a client of the running chain RPC server.
+func ChainClient(name string) (*rpc.Client, error) {
+	chain, err := loaders.LoadChainDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+	return chainClient(chain)
+}
+
+// chainClient returns a client of the loaded chain RPC server.
+func chainClient(chain *definitions.Chain) (*rpc.Client, error) {
+	name := chain.Name
+	container, err := util.DockerClient.InspectContainer(chain.Operations.SrvContainerName)
+	if err != nil || !container.State.Running {
+		return nil, fmt.Errorf("Chain %s is not running. Please start it with [eris chains start %[1]s]", name)
+	}
+
+	address, err := util.EndpointAddress(container, chain.Service, definitions.EndpointRPC)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"=>":      name,
+		"address": address,
+	}).Debug("Connecting to chain")
+	return rpc.NewClient(address), nil
+}
+
+// catStatus displays the chain node status.
+//
+//  do.Name   - name of the chain (required)
+//  do.Format - "json", a Go template applied to rpc.Status,
+//              or "" for a table
+//
+func catStatus(do *definitions.Do) error {
+	client, err := ChainClient(do.Name)
+	if err != nil {
+		return err
+	}
+	status, err := client.Status()
+	if err != nil {
+		return err
+	}
+
+	if do.Format != "" {
+		return printFormatted(status, do.Format, false)
+	}
+
+	node := status.NodeInfo
+	if node == nil {
+		node = new(rpc.NodeInfo)
+	}
+
+	tw := newTableWriter()
+	fmt.Fprintf(tw, "CHAIN ID\t%s\n", node.Network)
+	fmt.Fprintf(tw, "NODE\t%s\n", node.Moniker)
+	fmt.Fprintf(tw, "VERSION\t%s\n", node.Version)
+	fmt.Fprintf(tw, "HEIGHT\t%d\n", status.LatestBlockHeight)
+	fmt.Fprintf(tw, "LATEST BLOCK HASH\t%s\n", status.LatestBlockHash)
+	fmt.Fprintf(tw, "LATEST BLOCK TIME\t%s\n", status.BlockTime().Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(tw, "PUBLIC KEY\t%s\n", status.PubKey)
+	return tw.Flush()
+}
+
+// catValidators displays the chain validator set.
+//
+//  do.Name   - name of the chain (required)
+//  do.Format - "json", a Go template applied to every rpc.Validator,
+//              or "" for a table
+//
+func catValidators(do *definitions.Do) error {
+	client, err := ChainClient(do.Name)
+	if err != nil {
+		return err
+	}
+	validators, err := client.Validators()
+	if err != nil {
+		return err
+	}
+
+	switch do.Format {
+	case "":
+	case "json":
+		return printFormatted(validators, do.Format, false)
+	default:
+		return printFormatted(append(validators.BondedValidators, validators.UnbondingValidators...), do.Format, true)
+	}
+
+	tw := newTableWriter()
+	fmt.Fprintln(tw, "ADDRESS\tVOTING POWER\tSTATE\tPUBLIC KEY")
+	for _, validator := range validators.BondedValidators {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", validator.Address, validator.VotingPower, "bonded", validator.PubKey)
+	}
+	for _, validator := range validators.UnbondingValidators {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", validator.Address, validator.VotingPower, "unbonding", validator.PubKey)
+	}
+	return tw.Flush()
+}
+
+// catPeers displays the peers the chain node is connected to.
+//
+//  do.Name   - name of the chain (required)
+//  do.Format - "json", a Go template applied to every rpc.Peer,
+//              or "" for a table
+//
+func catPeers(do *definitions.Do) error {
+	client, err := ChainClient(do.Name)
+	if err != nil {
+		return err
+	}
+	info, err := client.NetInfo()
+	if err != nil {
+		return err
+	}
+
+	if do.Format != "" {
+		return printFormatted(info.Peers, do.Format, true)
+	}
+
+	tw := newTableWriter()
+	fmt.Fprintln(tw, "NODE\tADDRESS\tDIRECTION\tVERSION")
+	for _, peer := range info.Peers {
+		node := peer.NodeInfo
+		if node == nil {
+			node = new(rpc.NodeInfo)
+		}
+		direction := "inbound"
+		if peer.IsOutbound {
+			direction = "outbound"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", node.Moniker, node.RemoteAddr, direction, node.Version)
+	}
+	return tw.Flush()
+}
+
+// printFormatted writes v either as an indented JSON document or using
+// the Go template format. If each is true, v is a slice and the template
+// is applied to every item of it.
+func printFormatted(v interface{}, format string, each bool) error {
+	if format == "json" {
+		mar, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = config.GlobalConfig.Writer.Write(append(mar, '\n'))
+		return err
+	}
+
+	if each {
+		format = "{{range .}}" + format + "\n{{end}}"
+	} else {
+		format += "\n"
+	}
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("Cannot parse the --format template: %v", err)
+	}
+	return tmpl.Execute(config.GlobalConfig.Writer, v)
+}
+
+func newTableWriter() *tabwriter.Writer {
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	return tabwriter.NewWriter(config.GlobalConfig.Writer, 6, 1, 5, ' ', 0)
+}
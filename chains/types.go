@@ -0,0 +1,297 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Template kinds [eris chains make] reads (the do.Type values below).
+const (
+	ChainTypes   = "chain-types"
+	AccountTypes = "account-types"
+)
+
+// ShowType displays the chain type or account type template
+// after checking it is valid.
+//
+//  do.Type - kind of the template: "chain-types" or "account-types" (required)
+//  do.Name - name of the template (required)
+//
+func ShowType(do *definitions.Do) error {
+	file, err := typeFile(do.Type, do.Name)
+	if err != nil {
+		return err
+	}
+	if err := ValidateType(do.Type, do.Name); err != nil {
+		return err
+	}
+
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	config.GlobalConfig.Writer.Write(contents)
+	return nil
+}
+
+// NewType writes a new chain type or account type template into
+// the chain-types or account-types directory.
+//
+//  do.Type          - kind of the template: "chain-types" or "account-types" (required)
+//  do.Name          - name of the template (required)
+//  do.Definition    - template description (optional)
+//  do.AccountTypes  - chain types only: numbers of accounts of every account type (example: Full:1,Participant:25) (required)
+//  do.DefaultNumber - account types only: number of accounts made by default (optional)
+//  do.Tokens        - account types only: initial balance of every account (optional)
+//  do.Bond          - account types only: validator bond of every account (optional)
+//  do.Perms         - account types only: permissions granted (example: send,call) or set explicitly (example: root=0) (optional)
+//  do.Force         - overwrite the existing template (optional)
+//
+func NewType(do *definitions.Do) error {
+	dir, err := typeDir(do.Type)
+	if err != nil {
+		return err
+	}
+	if do.Name == "" || strings.ContainsAny(do.Name, `/\`) {
+		return fmt.Errorf("Invalid template name %q", do.Name)
+	}
+	if file, _ := typeFile(do.Type, do.Name); file != "" && !do.Force {
+		return fmt.Errorf("The %s template %s already exists. Please use the --force flag to overwrite it", do.Type, file)
+	}
+
+	var template interface{}
+	switch do.Type {
+	case ChainTypes:
+		counts, err := accountTypeCounts(do.AccountTypes)
+		if err != nil {
+			return err
+		}
+		chainType := &definitions.ChainType{
+			Name:         do.Name,
+			Definition:   do.Definition,
+			AccountTypes: counts,
+		}
+		if err := validateChainType(chainType); err != nil {
+			return err
+		}
+		template = chainType
+	case AccountTypes:
+		perms, err := permissionValues(do.Perms)
+		if err != nil {
+			return err
+		}
+		accountType := &definitions.AccountType{
+			Name:          do.Name,
+			Definition:    do.Definition,
+			DefaultNumber: do.DefaultNumber,
+			DefaultTokens: do.Tokens,
+			DefaultBond:   do.Bond,
+			Perms:         perms,
+		}
+		if err := validateAccountType(accountType); err != nil {
+			return err
+		}
+		template = accountType
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(dir, do.Name+".toml")
+	log.WithField("file", file).Warn("Writing template")
+	if err := writeType(file, template); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// EditType opens the chain type or account type template in the
+// default editor and checks it is still valid afterwards.
+//
+//  do.Type - kind of the template: "chain-types" or "account-types" (required)
+//  do.Name - name of the template (required)
+//
+func EditType(do *definitions.Do) error {
+	file, err := typeFile(do.Type, do.Name)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("file", file).Info("Editing template")
+	if err := Editor(file); err != nil {
+		return err
+	}
+	if err := ValidateType(do.Type, do.Name); err != nil {
+		return fmt.Errorf("%v. Please fix the template with [eris chains %s edit %s]", err, commandOfType(do.Type), do.Name)
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// RemoveType removes the chain type or account type template.
+//
+//  do.Type - kind of the template: "chain-types" or "account-types" (required)
+//  do.Name - name of the template (required)
+//
+func RemoveType(do *definitions.Do) error {
+	file, err := typeFile(do.Type, do.Name)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("file", file).Warn("Removing template")
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// ValidateType checks the permissions and amounts of the chain type
+// or account type template with the given name.
+func ValidateType(typ, name string) error {
+	switch typ {
+	case ChainTypes:
+		chainType, err := loaders.LoadChainType(name)
+		if err != nil {
+			return err
+		}
+		return validateChainType(chainType)
+	case AccountTypes:
+		accountType, err := loaders.LoadAccountType(name)
+		if err != nil {
+			return err
+		}
+		return validateAccountType(accountType)
+	}
+	return fmt.Errorf("Unknown template kind %q", typ)
+}
+
+func validateAccountType(accountType *definitions.AccountType) error {
+	switch {
+	case accountType.DefaultNumber < 0:
+		return fmt.Errorf("Account type %s: negative default number of accounts %d", accountType.Name, accountType.DefaultNumber)
+	case accountType.DefaultTokens < 0:
+		return fmt.Errorf("Account type %s: negative default tokens %d", accountType.Name, accountType.DefaultTokens)
+	case accountType.DefaultBond < 0:
+		return fmt.Errorf("Account type %s: negative default bond %d", accountType.Name, accountType.DefaultBond)
+	}
+
+	for name, value := range accountType.Perms {
+		if value != 0 && value != 1 {
+			return fmt.Errorf("Account type %s: permission %q should be 0 or 1, not %d", accountType.Name, name, value)
+		}
+	}
+	if _, _, err := permissionBits(accountType.Perms); err != nil {
+		return fmt.Errorf("Account type %s: %v", accountType.Name, err)
+	}
+	return nil
+}
+
+func validateChainType(chainType *definitions.ChainType) error {
+	types, err := loaders.LoadAccountTypes()
+	if err != nil {
+		return err
+	}
+
+	var total int
+	for name, count := range chainType.AccountTypes {
+		if count < 0 {
+			return fmt.Errorf("Chain type %s: negative number of %s accounts %d", chainType.Name, name, count)
+		}
+		total += count
+
+		var known bool
+		for _, accountType := range types {
+			if strings.EqualFold(accountType.Name, name) {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("Chain type %s: unknown account type %q. Please see the account types in %s", chainType.Name, name, AccountsTypePath)
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("Chain type %s: no accounts to make the chain of", chainType.Name)
+	}
+	return nil
+}
+
+// permissionValues parses the NAME or NAME=0|1 permissions.
+func permissionValues(perms []string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, perm := range perms {
+		name, value := perm, 1
+		if i := strings.Index(perm, "="); i >= 0 {
+			n, err := strconv.Atoi(perm[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid permission %q. Please use the NAME or NAME=0|1 format, e.g. call or root=0", perm)
+			}
+			name, value = perm[:i], n
+		}
+		values[strings.ToLower(name)] = value
+	}
+	return values, nil
+}
+
+func typeDir(typ string) (string, error) {
+	switch typ {
+	case ChainTypes:
+		return ChainTypePath, nil
+	case AccountTypes:
+		return AccountsTypePath, nil
+	}
+	return "", fmt.Errorf("Unknown template kind %q", typ)
+}
+
+func typeFile(typ, name string) (string, error) {
+	dir, err := typeDir(typ)
+	if err != nil {
+		return "", err
+	}
+	file := util.GetFileByNameAndType(typ, name)
+	if file == "" {
+		return "", fmt.Errorf("Unknown %s template %q. Please see the templates in %s", strings.TrimSuffix(typ, "s"), name, dir)
+	}
+	return file, nil
+}
+
+// commandOfType returns the [eris chains] subcommand
+// managing the templates of the kind.
+func commandOfType(typ string) string {
+	if typ == ChainTypes {
+		return "types"
+	}
+	return typ
+}
+
+func writeType(fileName string, template interface{}) error {
+	writer, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	writer.Write([]byte("# This is a TOML config file.\n# For more information, see https://github.com/toml-lang/toml\n\n"))
+	enc := toml.NewEncoder(writer)
+	enc.Indent = ""
+	return enc.Encode(template)
+}
@@ -54,8 +54,15 @@ func WriteChainDefinitionFile(chainDef *def.Chain, fileName string) error {
 		enc.Indent = ""
 		writer.Write([]byte("name = \"" + chainDef.Name + "\"\n"))
 		writer.Write([]byte("chain_id = \"" + chainDef.ChainID + "\"\n"))
+		if chainDef.Cluster != "" {
+			writer.Write([]byte("cluster = \"" + chainDef.Cluster + "\"\n"))
+		}
 		writer.Write([]byte("\n[service]\n"))
 		enc.Encode(chainDef.Service)
+		if chainDef.Dependencies != nil {
+			writer.Write([]byte("\n[dependencies]\n"))
+			enc.Encode(chainDef.Dependencies)
+		}
 		writer.Write([]byte("\n[maintainer]\n"))
 		enc.Encode(chainDef.Maintainer)
 	}
@@ -51,12 +51,20 @@ func buildActionsCommand() {
 // Actions Sub-sub-Commands
 var actionsImport = &cobra.Command{
 	Use:   "import NAME LOCATION",
-	Short: "import an action definition file from Github or IPFS",
+	Short: "import an action definition file from IPFS, Github, or the web",
 	Long: `import an action definition for your platform
 
-By default, Eris will import from IPFS`,
-	Example: "$ eris actions import \"do not use\" QmNUhPtuD9VtntybNqLgTTevUmgqs13eMvo2fkCwLLx5MX",
-	Run:     ImportAction,
+LOCATION can be one of:
+
+  ipfs:HASH                    a file in IPFS (a bare HASH also works)
+  github:ORG/REPO/PATH[@REF]   a file in a GitHub repository (REF is master by default)
+  https://HOST/PATH            a file on a web server
+  file:///PATH                 a file on the host (a bare PATH also works)
+
+The definition file is checked before it is written.`,
+	Example: `$ eris actions import "do not use" QmNUhPtuD9VtntybNqLgTTevUmgqs13eMvo2fkCwLLx5MX
+$ eris actions import "do not use" github:eris-ltd/eris-actions/do_not_use.toml`,
+	Run: ImportAction,
 }
 
 var actionsNew = &cobra.Command{
@@ -80,6 +88,7 @@ of Go text templates. The struct passed to the Go template is this
   type Definition struct {
     Name       string       // action name
     Definition string       // definition file name
+    Location   string       // "project", "global", or the ERIS_PATH directory
   }
 `,
 
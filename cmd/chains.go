@@ -39,23 +39,32 @@ away!`,
 func buildChainsCommand() {
 	Chains.AddCommand(chainsMake)
 	Chains.AddCommand(chainsNew)
+	Chains.AddCommand(chainsJoin)
 	Chains.AddCommand(chainsImport)
 	Chains.AddCommand(chainsList)
 	Chains.AddCommand(chainsCheckout)
 	Chains.AddCommand(chainsHead)
+	Chains.AddCommand(chainsHistory)
 	Chains.AddCommand(chainsPorts)
 	Chains.AddCommand(chainsEdit)
+	Chains.AddCommand(chainsConfig)
 	Chains.AddCommand(chainsStart)
 	Chains.AddCommand(chainsLogs)
 	Chains.AddCommand(chainsInspect)
 	Chains.AddCommand(chainsStop)
 	Chains.AddCommand(chainsExec)
 	Chains.AddCommand(chainsCat)
+	Chains.AddCommand(chainsQuery)
+	Chains.AddCommand(chainsEvents)
 	Chains.AddCommand(chainsExport)
+	Chains.AddCommand(chainsBackup)
+	Chains.AddCommand(chainsRestore)
+	Chains.AddCommand(chainsClone)
 	Chains.AddCommand(chainsRename)
 	Chains.AddCommand(chainsUpdate)
 	Chains.AddCommand(chainsRestart)
 	Chains.AddCommand(chainsRemove)
+	buildChainsTypesCommands()
 	addChainsFlags()
 }
 
@@ -85,9 +94,9 @@ The make process will *not* start a chain for you. You will want to use
 the [eris chains new chainName --dir chainName] for that which will import all
 of the files which make creates into containers and start your shiny new chain.
 
-If you have any questions on eris chains make, please see the eris-cm (chain manager)
-documentation here:
-https://docs.erisindustries.com/documentation/eris-cm/latest/eris-cm/`,
+Account types are read from ~/.eris/chains/account-types and chain types from
+~/.eris/chains/chain-types (see [eris chains account-types] and [eris chains types]).
+Keys are generated by the keys service.`,
 	Example: `$ eris chains make myChain -- will use the chain-making wizard and make your chain named myChain (interactive)
 $ eris chains make myChain --chain-type=simplechain --  will use the chain type definition files to make your chain named myChain (non-interactive)
 $ eris chains make myChain --account-types=Root:1,Developer:0,Validator:0,Participant:1 -- will use the flag to make your chain named myChain (non-interactive)
@@ -124,11 +133,28 @@ unless the --serverconf or --dir flag is passed.
 If you would like to create a genesis.json then please utilize [eris chains make]
 
 You can redefine the chain ports accessible over the network with the --ports flag.
+If a host port is already taken by another container or process, the command
+fails naming the owner, unless the --auto-ports flag is given to publish such
+ports to free host ports instead (see [eris chains ports] for the result).
+
+With the --nodes flag a local cluster of validator nodes NAME_0..NAME_N-1
+is created instead. Each node gets its own validator key, data container,
+and chain definition file; all nodes share one genesis.json listing every
+validator and are seeded with each other. The first node publishes ports
+as usual, the rest publish to random host ports (see [eris chains ports]).
+[eris chains stop NAME], [eris chains rm NAME], and [eris chains ls] treat
+the cluster as one chain.
+
+With the --wait flag the command doesn't exit until the chain (or every
+cluster node) produces blocks or the --timeout number of seconds passes.
 `,
 	Run: NewChain,
 	Example: `$ eris chains new simplechain --ports 4000 -- map the first port from the definition file to the host port 40000
 $ eris chains new simplechain --ports 40000,50000- -- redefine the first and the second port mapping and autoincrement the rest
-$ eris chains new simplechain --ports 46656:50000 -- redefine the specific port mapping (published host port:exposed container port)`,
+$ eris chains new simplechain --ports 46656:50000 -- redefine the specific port mapping (published host port:exposed container port)
+$ eris chains new simplechain --auto-ports -- publish the ports already taken on the host to free ports
+$ eris chains new cluster --nodes 4 -- create and start four validator nodes cluster_0..cluster_3
+$ eris chains new simplechain --dir simplechain --wait -- create and start the chain and wait until it produces blocks`,
 }
 
 var chainsRegister = &cobra.Command{
@@ -182,9 +208,14 @@ for the -k flag is this
   type Definition struct {
     Name       string       // chain name
     Definition string       // definition file name
+    Location   string       // "project", "global", or the ERIS_PATH directory
   }
 
-The -k flag displays the known definition files. `,
+The -k flag displays the known definition files. Definitions are looked up
+in the ./.eris/chains project directory, then in the chains directory
+of every ERIS_PATH directory, then in ~/.eris/chains. The first
+definition found takes precedence and its location is displayed unless
+it is global.`,
 
 	Run: ListChains,
 	Example: `$ eris chains ls -f '{{.ShortName}}\t{{.Info.Config.Image}}\t{{ports .Info}}'
@@ -193,18 +224,27 @@ $ eris chains ls -f '{{.ShortName}}\t{{.Info.State}}'`,
 
 var chainsImport = &cobra.Command{
 	Use:   "import NAME LOCATION",
-	Short: "import a chain definition file from Github or IPFS",
+	Short: "import a chain definition file from IPFS, Github, or the web",
 	Long: `import a chain definition for your platform
 
-By default, Eris will import from IPFS.
+LOCATION can be one of:
+
+  ipfs:HASH                    a file in IPFS (a bare HASH also works)
+  github:ORG/REPO/PATH[@REF]   a file in a GitHub repository (REF is master by default)
+  https://HOST/PATH            a file on a web server
+  file:///PATH                 a file on the host (a bare PATH also works)
+
+The definition file is checked before it is written.
 
 To list known chains use: [eris chains ls --known].`,
-	Example: "$ eris chains import 2gather QmNUhPtuD9VtntybNqLgTTevUmgqs13eMvo2fkCwLLx5MX",
-	Run:     ImportChain,
+	Example: `$ eris chains import 2gather QmNUhPtuD9VtntybNqLgTTevUmgqs13eMvo2fkCwLLx5MX
+$ eris chains import 2gather github:eris-ltd/eris-chains/2gather.toml@v0.1
+$ eris chains import 2gather https://example.com/chains/2gather.toml`,
+	Run: ImportChain,
 }
 
 var chainsCheckout = &cobra.Command{
-	Use:   "checkout NAME",
+	Use:   "checkout [NAME|-]",
 	Short: "check out a chain",
 	Long: `check out a chain
 
@@ -214,27 +254,50 @@ passing in a --chain flag. If a --chain is passed to any command accepting
 --chain, the --chain which is passed will overwrite any checked out chain.
 
 If command is given without arguments it will clear the head and there will
-be no chain checked out.`,
+be no chain checked out. If command is given a "-" argument it will check
+out the previously checked out chain.
+
+With the --local flag the chain is checked out for the current project only:
+its name is written to the .eris-chain file in the working directory, which
+overrides the globally checked out chain for commands run in that directory.
+To remove the project-local checkout use [eris chains checkout --local].
+
+To list the recently checked out chains use [eris chains history].`,
 	Run: CheckoutChain,
+	Example: `$ eris chains checkout simplechain
+$ eris chains checkout - -- switch back to the previously checked out chain
+$ eris chains checkout --local simplechain -- check out a chain for the project in the working directory`,
+}
+
+var chainsHistory = &cobra.Command{
+	Use:   "history",
+	Short: "list recently checked out chains",
+	Long: `list recently checked out chains, the latest first,
+along with the times they were checked out
+
+To switch back to the previously checked out chain use
+[eris chains checkout -].`,
+	Run: HistoryChain,
 }
 
 var chainsPorts = &cobra.Command{
-	Use:   "ports NAME [PORT]...",
-	Short: "print port mappings",
-	Long: `print port mappings
+	Use:   "ports NAME [ENDPOINT|PORT]...",
+	Short: "print chain endpoints and port mappings",
+	Long: `print chain endpoints and port mappings
 
 The [eris chains ports] command is mostly a developer
-convenience function. It returns a machine readable
-port mapping of a port which is exposed inside the
-container to what that port is mapped to on the host.
+convenience function. It returns the named chain endpoints
+(rpc, p2p, api, or the names from the [service.endpoints] table
+of the chain definition file), the ports they are exposed at
+inside the container, and the host addresses they are mapped to.
 
 This is useful when stitching together chain networks which
 need to know how to connect into a specific chain (perhaps
 with or without a container number) container.`,
-	Example: `$ eris chains ports myChain 1337 -- will display what port on the host is mapped to the eris:db API port
-$ eris chains ports myChain 46656 -- will display what port on the host is mapped to the eris:db peer port
-$ eris chains ports myChain 46657 -- will display what port on the host is mapped to the eris:db rpc port
-$ eris chains ports myChain -- will display all mappings`,
+	Example: `$ eris chains ports myChain rpc -- will display the host address of the eris:db rpc endpoint
+$ eris chains ports myChain p2p -- will display the host address of the eris:db peer endpoint
+$ eris chains ports myChain 1337 -- will display what port on the host is mapped to the eris:db API port
+$ eris chains ports myChain -- will display all endpoints`,
 	Run: PortsChain,
 }
 
@@ -262,6 +325,32 @@ will default to that if you wise.`,
 	Run: EditChain,
 }
 
+var chainsConfig = &cobra.Command{
+	Use:   "config NAME get|set|edit [KEY[=VALUE]...]",
+	Short: "display or change the chain node config",
+	Long: `display or change the config.toml file of the chain node
+
+Unlike [eris chains edit], which edits the chain definition file on the host,
+config works on the config.toml file the chain node reads from its data
+container: the moniker, seeds, fast_sync, log_level, and other settings.
+
+The get subcommand displays the values of the given keys or the whole file.
+The set subcommand changes the values of the KEY=VALUE pairs and the edit
+subcommand opens the file in the default editor. The values of the known
+keys are checked to be of the right type (true or false for fast_sync,
+HOST:PORT addresses for seeds, etc.); use the --force flag to set the keys
+the marmots don't know about.
+
+If the chain is running, the command offers to restart it to apply the
+changes (the --yes flag restarts it without asking).`,
+	Example: `$ eris chains config simplechain get -- display the config.toml file
+$ eris chains config simplechain get moniker seeds -- display the moniker and seeds values
+$ eris chains config simplechain set fast_sync=false log_level=debug -- change the values
+$ eris chains config simplechain set seeds=10.0.0.1:46656,10.0.0.2:46656 --yes -- change the seeds and restart the chain
+$ eris chains config simplechain edit -- edit the config.toml file in the default editor`,
+	Run: ConfigChain,
+}
+
 var chainsStart = &cobra.Command{
 	Use:   "start",
 	Short: "start a blockchain",
@@ -273,9 +362,17 @@ background so its logs will not be viewable from the command line.
 To stop the chain use:      [eris chains stop NAME]
 To view a chain's logs use: [eris chains logs NAME]
 
-You can redefine the chain ports accessible over the network with the --ports flag.
+You can redefine the chain ports accessible over the network with the --ports flag,
+or use the --auto-ports flag to publish the ports which are already taken to free ports.
 See the [eris chains new] command for examples.
+
+With the --wait flag the command doesn't exit until the chain answers
+RPC status requests and its block height moves. If that doesn't happen
+within the --timeout number of seconds, the command fails and displays
+the last lines of the chain logs.
 `,
+	Example: `$ eris chains start simplechain --wait -- start the chain and wait until it produces blocks
+$ eris chains start simplechain --wait --timeout 120 -- same, but give the chain two minutes to boot`,
 	Run: StartChain,
 }
 
@@ -324,6 +421,91 @@ Command will return a machine readable version of the IPFS hash.`,
 	Run: ExportChain,
 }
 
+var chainsBackup = &cobra.Command{
+	Use:   "backup NAME [FILE]",
+	Short: "back up a blockchain",
+	Long: `back up a blockchain into a tarball
+
+The backup contains the contents of the chain's data container
+(the blockchain data, priv_validator.json, config.toml, etc.), the
+chain definition file, and a manifest with the image versions used.
+
+A running chain is stopped for the duration of the backup and
+restarted afterwards.
+
+The backup file name defaults to NAME_<timestamp>.tar.gz.
+
+**The backup will contain the validator's private key, so please
+be aware of that.**`,
+	Example: `$ eris chains backup simplechain -- back up the chain into simplechain_<timestamp>.tar.gz
+$ eris chains backup simplechain backup.tar.gz -- back up the chain into backup.tar.gz`,
+	Run: BackupChain,
+}
+
+var chainsRestore = &cobra.Command{
+	Use:   "restore FILE [NEWNAME]",
+	Short: "restore a blockchain from a backup",
+	Long: `restore a blockchain from a backup made by [eris chains backup]
+
+Restore recreates the chain's data container and the chain
+definition file. The chain is restored under its original name
+unless NEWNAME is given. Existing chains are not overwritten.
+
+Use [eris chains start NAME] to start the restored chain.`,
+	Example: `$ eris chains restore backup.tar.gz -- restore the chain under its original name
+$ eris chains restore backup.tar.gz anotherchain -- restore the chain under the anotherchain name`,
+	Run: RestoreChain,
+}
+
+var chainsJoin = &cobra.Command{
+	Use:   "join NAME",
+	Short: "join an existing blockchain network",
+	Long: `create a node of an existing blockchain network and start it
+
+The genesis file of the network is fetched from the --genesis location:
+an IPFS hash (ipfs:HASH), a GitHub file (github:ORG/REPO/PATH[@REF]),
+a URL, or a local file. If the --genesis-hash flag is given, the SHA256
+hash of the file must match it.
+
+The node key is generated by the keys service unless a priv_validator.json
+file is given with the --priv flag. The genesis.json, priv_validator.json,
+and config.toml (based on ~/.eris/chains/default/config.toml with the --seeds
+set) files are written into ~/.eris/chains/NAME and the node is started
+from there, like with [eris chains new NAME --dir NAME].
+
+The node joins the network as an observer, following the blocks the
+validators make, unless its key is listed among the genesis validators.
+With the --validator flag the command fails if it isn't, displaying the
+validators.csv line to send to the network maintainers. Once they add it
+to the genesis, join again with the --priv ~/.eris/chains/NAME/priv_validator.json
+flag.`,
+	Example: `$ eris chains join testnet --genesis ipfs:QmVdjShTMLAD6YTEgQ1wen1ym4p19ZWepCYTf1MNC1f1Ft --seeds 10.0.0.1:46656,10.0.0.2:46656
+$ eris chains join testnet --genesis https://example.com/genesis.json --genesis-hash 5a3f...e1 --seeds 10.0.0.1:46656
+$ eris chains join testnet --genesis genesis.json --seeds 10.0.0.1:46656 --priv priv_validator.json --validator`,
+	Run: JoinChain,
+}
+
+var chainsClone = &cobra.Command{
+	Use:   "clone SRC DST",
+	Short: "copy a blockchain into a new independent chain",
+	Long: `copy a blockchain into a new independent chain
+
+Clone copies the data container of the SRC chain into a new data
+container and writes a new chain definition file for the DST chain.
+A running SRC chain is stopped for the duration of the copy and
+restarted afterwards. The DST chain is not started.
+
+By default the clone keeps the chain ID and the validator key of
+the original, so the two chains shouldn't be connected to each other
+or run on the same network. Use the --chain-id and --new-keys flags
+to rewrite them. In that case the blockchain data of the clone is
+reset to the genesis state, because the existing blocks are tied to
+the original chain ID and validators.`,
+	Example: `$ eris chains clone devchain experiment -- copy the devchain state into the experiment chain
+$ eris chains clone devchain experiment --chain-id experiment --new-keys -- make an independent chain from the devchain setup`,
+	Run: CloneChain,
+}
+
 var chainsRename = &cobra.Command{
 	Use:   "rename OLD_NAME NEW_NAME",
 	Short: "rename a blockchain",
@@ -368,42 +550,111 @@ var chainsRestart = &cobra.Command{
 }
 
 var chainsCat = &cobra.Command{
-	Use:     "cat NAME [config|genesis|status|validators]",
-	Short:   "display chain information",
-	Long:    `display chain information`,
+	Use:   "cat NAME [config|genesis|status|validators|peers]",
+	Short: "display chain information",
+	Long: `display chain information
+
+The status, validators, and peers information is queried from the
+running chain and displayed as a table. Use the --format flag to get
+a JSON document (--format json) or to apply a Go template: to the
+status, or to every validator or peer. See the Status, Validator, and
+Peer types of the github.com/eris-ltd/eris-cli/rpc package for the
+available fields.`,
 	Aliases: []string{"plop"},
 	Example: `$ eris chains cat simplechain -- display the chain definition file
 $ eris chains cat simplechain config -- display the config.toml file from inside the container
 $ eris chains cat simplechain genesis -- display the genesis.json file from the container
 $ eris chains cat simplechain status -- display chain status
-$ eris chains cat simplechain validators -- display chain validators`,
+$ eris chains cat simplechain status --format '{{.LatestBlockHeight}}' -- display the chain block height
+$ eris chains cat simplechain validators -- display chain validators
+$ eris chains cat simplechain validators --format '{{.Address}} {{.VotingPower}}' -- display validator addresses and voting power
+$ eris chains cat simplechain peers --format json -- display the peers the chain is connected to as JSON`,
 	Run: CatChain,
 }
 
+var chainsQuery = &cobra.Command{
+	Use:   "query NAME block|tx|account|storage ARGS",
+	Short: "query a running chain",
+	Long: `query a running chain for a block, a transaction, an account, or a
+contract storage value
+
+The query is sent to the chain RPC server published by the chain container.
+The answer is displayed as a JSON document unless the --format flag
+with a Go template is given. See the BlockResult, Account, and Storage
+types of the github.com/eris-ltd/eris-cli/rpc package for the available
+fields. Addresses, hashes, and keys are hex strings.`,
+	Example: `$ eris chains query simplechain block 10 -- display the block at the height 10
+$ eris chains query simplechain block 10 --format '{{.BlockMeta.Hash}}' -- display the hash of the block at the height 10
+$ eris chains query simplechain tx 5F3A...C1 -- display the transaction with the given hash
+$ eris chains query simplechain account 1A2B...FF --format '{{.Balance}}' -- display the account balance
+$ eris chains query simplechain storage 1A2B...FF 0000...01 -- display the contract storage value under the given key`,
+	Run: QueryChain,
+}
+
+var chainsEvents = &cobra.Command{
+	Use:   "events NAME",
+	Short: "display chain events as they happen",
+	Long: `subscribe to the running chain events and display them as they happen
+
+Events are received over the chain websocket RPC endpoint and displayed
+one per line, either as a short summary or (with the --json flag) as
+JSON documents. If the chain is restarted, the command reconnects to it.
+Press Ctrl+C to stop.
+
+Use the --filter flag to choose the events (new blocks by default):
+
+  block            - new blocks
+  account:ADDRESS  - transactions sending from or to the account
+  call:ADDRESS     - calls of the contract
+  log:ADDRESS      - events emitted by the contract
+
+Any other filter is used as a raw chain event ID, e.g. NewRound.`,
+	Example: `$ eris chains events simplechain -- display new blocks
+$ eris chains events simplechain --filter log:1A2B...FF --json -- display contract events as JSON
+$ eris chains events simplechain --filter block,account:1A2B...FF -- display new blocks and account transactions`,
+	Run: EventsChain,
+}
+
 func addChainsFlags() {
 	chainsMake.PersistentFlags().StringSliceVarP(&do.AccountTypes, "account-types", "", []string{}, "what number of account types should we use? find these in ~/.eris/chains/account-types; incompatible with and overrides chain-type")
 	chainsMake.PersistentFlags().StringVarP(&do.ChainType, "chain-type", "", "", "which chain type definition should we use? find these in ~/.eris/chains/chain-types")
 	chainsMake.PersistentFlags().BoolVarP(&do.Tarball, "tar", "", false, "instead of making directories in ~/.eris/chains, make tarballs; incompatible with and overrides zip")
 	chainsMake.PersistentFlags().BoolVarP(&do.ZipFile, "zip", "", false, "instead of making directories in ~/.eris/chains, make zip files")
-	chainsMake.PersistentFlags().BoolVarP(&do.Output, "output", "", true, "should eris-cm provide an output of its job")
+	chainsMake.PersistentFlags().StringVarP(&do.ChainID, "chain-id", "", "", "chain ID of the new chain (defaults to the chain name)")
+	chainsMake.PersistentFlags().BoolVarP(&do.Output, "output", "", true, "display the accounts made")
 	chainsMake.PersistentFlags().BoolVarP(&do.Known, "known", "", false, "use csv for a set of known keys to assemble genesis.json (requires both --accounts and --validators flags")
 	chainsMake.PersistentFlags().StringVarP(&do.ChainMakeActs, "accounts", "", "", "comma separated list of the accounts.csv files you would like to utilize (requires --known flag)")
 	chainsMake.PersistentFlags().StringVarP(&do.ChainMakeVals, "validators", "", "", "comma separated list of the validators.csv files you would like to utilize (requires --known flag)")
 	chainsMake.PersistentFlags().BoolVarP(&do.RmD, "data", "x", true, "remove data containers after stopping")
+	chainsMake.PersistentFlags().MarkDeprecated("data", "chains make no longer runs containers")
 
 	buildFlag(chainsNew, do, "dir", "chain")
 	buildFlag(chainsNew, do, "env", "chain")
 	buildFlag(chainsNew, do, "publish", "chain")
 	buildFlag(chainsNew, do, "ports", "chain")
+	buildFlag(chainsNew, do, "auto-ports", "chain")
 	buildFlag(chainsNew, do, "links", "chain")
 	chainsNew.PersistentFlags().BoolVarP(&do.Logrotate, "logrotate", "z", false, "turn on logrotate as a dependency to handle long output")
+	chainsNew.PersistentFlags().UintVarP(&do.N, "nodes", "", 1, "number of validator nodes to create the chain with")
+	buildFlag(chainsNew, do, "wait", "chain")
+	buildFlag(chainsNew, do, "wait-timeout", "chain")
 
 	buildFlag(chainsStart, do, "publish", "chain")
 	buildFlag(chainsStart, do, "ports", "chain")
+	buildFlag(chainsStart, do, "auto-ports", "chain")
 	buildFlag(chainsStart, do, "env", "chain")
 	buildFlag(chainsStart, do, "links", "chain")
+	buildFlag(chainsStart, do, "wait", "chain")
+	buildFlag(chainsStart, do, "wait-timeout", "chain")
 	chainsStart.PersistentFlags().BoolVarP(&do.Logrotate, "logrotate", "z", false, "turn on logrotate as a dependency to handle long output")
 
+	chainsCat.Flags().StringVarP(&do.Format, "format", "f", "", "output format of status, validators, and peers: json or a Go template")
+
+	chainsQuery.Flags().StringVarP(&do.Format, "format", "f", "", "output format: json (default) or a Go template")
+
+	chainsEvents.Flags().StringSliceVarP(&do.Filter, "filter", "", nil, "comma separated list of events to display (see above)")
+	chainsEvents.Flags().BoolVarP(&do.JSON, "json", "", false, "display every event as a JSON document")
+
 	buildFlag(chainsLogs, do, "follow", "chain")
 	buildFlag(chainsLogs, do, "tail", "chain")
 
@@ -430,12 +681,42 @@ func addChainsFlags() {
 	buildFlag(chainsStop, do, "timeout", "chain")
 	buildFlag(chainsStop, do, "volumes", "chain")
 
+	buildFlag(chainsBackup, do, "timeout", "chain")
+
+	buildFlag(chainsClone, do, "timeout", "chain")
+	chainsConfig.Flags().BoolVarP(&do.Force, "force", "f", false, "allow unknown config keys to be set")
+	chainsConfig.Flags().BoolVarP(&do.Yes, "yes", "y", false, "restart the running chain without asking")
+	buildFlag(chainsConfig, do, "timeout", "chain")
+
+	chainsJoin.Flags().StringVarP(&do.GenesisFile, "genesis", "", "", "location of the genesis file: ipfs:HASH, github:ORG/REPO/PATH, URL, or file (required)")
+	chainsJoin.Flags().StringVarP(&do.GenesisHash, "genesis-hash", "", "", "expected SHA256 hash of the genesis file")
+	chainsJoin.Flags().StringSliceVarP(&do.Seeds, "seeds", "", nil, "comma separated list of HOST:PORT addresses of the network nodes")
+	chainsJoin.Flags().StringVarP(&do.Priv, "priv", "", "", "priv_validator.json file of the node key (a new key is generated by default)")
+	chainsJoin.Flags().BoolVarP(&do.Validator, "validator", "", false, "fail unless the node key is a genesis validator")
+	chainsJoin.Flags().StringVarP(&do.ChainID, "chain-id", "", "", "expected chain ID of the genesis file")
+	chainsJoin.Flags().StringVarP(&do.Gateway, "node-addr", "", "", "address of the node")
+	chainsJoin.Flags().StringSliceVarP(&do.ConfigOpts, "options", "", nil, "comma separated list of KEY=VALUE config options for the node")
+	buildFlag(chainsJoin, do, "config", "chain")
+	buildFlag(chainsJoin, do, "env", "chain")
+	buildFlag(chainsJoin, do, "publish", "chain")
+	buildFlag(chainsJoin, do, "ports", "chain")
+	buildFlag(chainsJoin, do, "auto-ports", "chain")
+	buildFlag(chainsJoin, do, "links", "chain")
+	buildFlag(chainsJoin, do, "wait", "chain")
+	buildFlag(chainsJoin, do, "wait-timeout", "chain")
+
+	chainsClone.Flags().StringVarP(&do.ChainID, "chain-id", "", "", "chain ID of the new chain (defaults to the source chain ID)")
+	chainsClone.Flags().BoolVarP(&do.NewKeys, "new-keys", "", false, "generate a new validator key for the new chain")
+
+	chainsCheckout.Flags().BoolVarP(&do.Local, "local", "", false, "check out the chain for the project in the working directory only")
+
 	buildFlag(chainsList, do, "known", "chain")
 	chainsList.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
 	chainsList.Flags().BoolVarP(&do.All, "all", "a", false, "show extended output")
 	chainsList.Flags().BoolVarP(&do.Quiet, "quiet", "q", false, "show a list of chain names")
 	chainsList.Flags().StringVarP(&do.Format, "format", "f", "", "alternate format for columnized output")
 	chainsList.Flags().BoolVarP(&do.Running, "running", "r", false, "show running containers")
+	chainsList.Flags().BoolVarP(&do.Health, "health", "", false, "run the health checks of running containers (otherwise their health is shown as unknown)")
 }
 
 func StartChain(cmd *cobra.Command, args []string) {
@@ -503,11 +784,6 @@ func MakeChain(cmd *cobra.Command, args []string) {
 		cmd.Help()
 		IfExit(fmt.Errorf("\nThe --account-types and --chain-type flags are incompatible with the --known flag. Please use only one of these."))
 	}
-	if !do.Known {
-		config.GlobalConfig.InteractiveWriter = os.Stdout
-		config.GlobalConfig.InteractiveErrorWriter = os.Stderr
-		do.Operations.Terminal = true
-	}
 
 	IfExit(chns.MakeChain(do))
 }
@@ -516,12 +792,23 @@ func NewChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(1, "ge", cmd, args))
 	do.Name = args[0]
 	do.Run = true
-	if do.Name != "default" && do.Path == "" { //not default & no --dir given
+	if do.Name != "default" && do.Path == "" && do.N <= 1 { //not default & no --dir given
 		IfExit(errors.New("cannot omit the --dir flag unless chainName == default"))
 	}
 	IfExit(chns.NewChain(do))
 }
 
+func JoinChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	do.Run = true
+	if do.GenesisFile == "" {
+		cmd.Help()
+		IfExit(fmt.Errorf("\nThe --genesis flag is required to join a chain."))
+	}
+	IfExit(chns.JoinChain(do))
+}
+
 func RegisterChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(2, "ge", cmd, args))
 	do.Name = args[0]
@@ -549,6 +836,10 @@ func CurrentChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.CurrentChain(do))
 }
 
+func HistoryChain(cmd *cobra.Command, args []string) {
+	IfExit(chns.HistoryChain(do))
+}
+
 func CatChain(cmd *cobra.Command, args []string) {
 	// [csk]: if no args should we just start the checkedout chain?
 	IfExit(ArgCheck(1, "ge", cmd, args))
@@ -560,6 +851,28 @@ func CatChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.CatChain(do))
 }
 
+func ConfigChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "ge", cmd, args))
+	do.Name = args[0]
+	do.Type = args[1]
+	do.Operations.Args = args[2:]
+	IfExit(chns.ConfigChain(do))
+}
+
+func QueryChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "ge", cmd, args))
+	do.Name = args[0]
+	do.Type = args[1]
+	do.Operations.Args = args[2:]
+	IfExit(chns.QueryChain(do))
+}
+
+func EventsChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	IfExit(chns.EventsChain(do))
+}
+
 func PortsChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(1, "ge", cmd, args))
 	do.Name = args[0]
@@ -600,6 +913,31 @@ func ExportChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.ExportChain(do))
 }
 
+func BackupChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "ge", cmd, args))
+	do.Name = args[0]
+	if len(args) > 1 {
+		do.Path = args[1]
+	}
+	IfExit(chns.BackupChain(do))
+}
+
+func RestoreChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "ge", cmd, args))
+	do.Path = args[0]
+	if len(args) > 1 {
+		do.NewName = args[1]
+	}
+	IfExit(chns.RestoreChain(do))
+}
+
+func CloneChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "eq", cmd, args))
+	do.Name = args[0]
+	do.NewName = args[1]
+	IfExit(chns.CloneChain(do))
+}
+
 func ListChains(cmd *cobra.Command, args []string) {
 	if do.All {
 		do.Format = "extended"
@@ -613,7 +951,7 @@ func ListChains(cmd *cobra.Command, args []string) {
 	if do.Known {
 		IfExit(list.Known("chains", do.Format))
 	} else {
-		IfExit(list.Containers(def.TypeChain, do.Format, do.Running))
+		IfExit(list.Containers(def.TypeChain, do.Format, do.Running, do.Health))
 	}
 }
 
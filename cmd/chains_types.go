@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+
+	chns "github.com/eris-ltd/eris-cli/chains"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/list"
+
+	. "github.com/eris-ltd/common/go/common"
+	"github.com/spf13/cobra"
+)
+
+var chainsTypes = &cobra.Command{
+	Use:   "types",
+	Short: "manage chain type templates",
+	Long: `manage the chain type templates [eris chains make --chain-type] uses
+
+Chain types are TOML files in ~/.eris/chains/chain-types which describe
+a typical chain as a number of accounts of every account type, e.g.
+
+  name = "simplechain"
+  definition = "a chain with a single validator"
+
+  [account_types]
+  Full = 1
+
+See [eris chains account-types] for the account types.`,
+	Run: func(cmd *cobra.Command, args []string) { cmd.Help() },
+}
+
+var chainsAccountTypes = &cobra.Command{
+	Use:   "account-types",
+	Short: "manage account type templates",
+	Long: `manage the account type templates [eris chains make] uses
+
+Account types are TOML files in ~/.eris/chains/account-types which describe
+a kind of accounts the chain is made of: their default number, balance,
+validator bond (accounts with no bond aren't validators), and permissions, e.g.
+
+  name = "Participant"
+  definition = "users of the chain applications"
+  default_number = 1
+  default_tokens = 9999999999
+  default_bond = 0
+
+  [perms]
+  send = 1
+  call = 1
+  root = 0
+
+Permissions are root, send, call, create_contract, create_account, bond,
+name, has_base, set_base, unset_base, set_global, has_role, add_role,
+and rm_role, and every one is either granted (1) or denied (0).`,
+	Run: func(cmd *cobra.Command, args []string) { cmd.Help() },
+}
+
+func buildChainsTypesCommands() {
+	for _, parent := range []struct {
+		cmd *cobra.Command
+		typ string
+	}{
+		{chainsTypes, chns.ChainTypes},
+		{chainsAccountTypes, chns.AccountTypes},
+	} {
+		typ := parent.typ
+		what := "chain type"
+		if typ == chns.AccountTypes {
+			what = "account type"
+		}
+
+		ls := &cobra.Command{
+			Use:   "ls",
+			Short: fmt.Sprintf("list %s templates", what),
+			Long: fmt.Sprintf(`list %s templates
+
+The --json flag dumps the templates information in the JSON format.
+The -f flag specifies an alternate format for the list, using the syntax
+of Go text templates. The struct passed to the Go template is this
+
+  type Definition struct {
+    Name       string       // template name
+    Definition string       // template file name
+  }`, what),
+			Run: func(cmd *cobra.Command, args []string) { ListTypes(typ) },
+		}
+		ls.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+		ls.Flags().BoolVarP(&do.Quiet, "quiet", "q", false, "show a list of template names")
+		ls.Flags().StringVarP(&do.Format, "format", "f", "", "alternate format for columnized output")
+
+		show := &cobra.Command{
+			Use:   "show NAME",
+			Short: fmt.Sprintf("display a %s template", what),
+			Run:   func(cmd *cobra.Command, args []string) { ManageType(cmd, args, typ, chns.ShowType) },
+		}
+
+		create := &cobra.Command{
+			Use:   "new NAME",
+			Short: fmt.Sprintf("create a %s template", what),
+			Run:   func(cmd *cobra.Command, args []string) { ManageType(cmd, args, typ, chns.NewType) },
+		}
+		create.Flags().StringVarP(&do.Definition, "definition", "", "", fmt.Sprintf("description of the %s", what))
+		create.Flags().BoolVarP(&do.Force, "force", "f", false, "overwrite the existing template")
+		if typ == chns.ChainTypes {
+			create.Example = `$ eris chains types new simplechain --account-types=Full:1 -- a chain of a single full account
+$ eris chains types new company --account-types=Root:1,Validator:3,Participant:10 --definition "company wide chain"`
+			create.Flags().StringSliceVarP(&do.AccountTypes, "account-types", "", nil, "numbers of accounts of every account type (example: Full:1,Participant:25)")
+		} else {
+			create.Example = `$ eris chains account-types new Participant --number 10 --tokens 9999999999 --perms send,call
+$ eris chains account-types new Validator --bond 9999999998 --tokens 9999999999 --perms bond,root=0`
+			create.Flags().IntVarP(&do.DefaultNumber, "number", "", 0, "number of accounts made by default")
+			create.Flags().Int64VarP(&do.Tokens, "tokens", "", 0, "initial balance of every account")
+			create.Flags().Int64VarP(&do.Bond, "bond", "", 0, "validator bond of every account (accounts with no bond aren't validators)")
+			create.Flags().StringSliceVarP(&do.Perms, "perms", "", nil, "comma separated list of permissions granted (NAME) or set explicitly (NAME=0 or NAME=1)")
+		}
+
+		edit := &cobra.Command{
+			Use:   "edit NAME",
+			Short: fmt.Sprintf("edit a %s template", what),
+			Run:   func(cmd *cobra.Command, args []string) { ManageType(cmd, args, typ, chns.EditType) },
+		}
+
+		remove := &cobra.Command{
+			Use:   "rm NAME",
+			Short: fmt.Sprintf("remove a %s template", what),
+			Run:   func(cmd *cobra.Command, args []string) { ManageType(cmd, args, typ, chns.RemoveType) },
+		}
+
+		parent.cmd.AddCommand(ls, show, create, edit, remove)
+		Chains.AddCommand(parent.cmd)
+	}
+}
+
+func ListTypes(typ string) {
+	if do.Quiet {
+		do.Format = "{{.Name}}"
+	}
+	if do.JSON {
+		do.Format = "json"
+	}
+	IfExit(list.Known(typ, do.Format))
+}
+
+func ManageType(cmd *cobra.Command, args []string, typ string, manage func(*def.Do) error) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	do.Type = typ
+	IfExit(manage(do))
+}
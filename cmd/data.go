@@ -156,7 +156,7 @@ func ListData(cmd *cobra.Command, args []string) {
 	if do.JSON {
 		do.Format = "json"
 	}
-	IfExit(list.Containers(def.TypeData, do.Format, false))
+	IfExit(list.Containers(def.TypeData, do.Format, false, false))
 }
 
 func RenameData(cmd *cobra.Command, args []string) {
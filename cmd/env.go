@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/eris-ltd/eris-cli/chains"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	"github.com/spf13/cobra"
+)
+
+var Env = &cobra.Command{
+	Use:   "env [CHAIN]",
+	Short: "export connection variables for a chain and services",
+	Long: `print the environment variables applications need to connect
+to a running chain, the keys server, and services
+
+The [eris env] command resolves the chain ID, the default account,
+and the host:port addresses of the chain and services endpoints
+(see [eris chains ports]) from the running containers. Addresses
+point to the Docker host, so they also work with a remote Docker
+host (the --machine flag or the DOCKER_HOST variable).
+
+If no chain is given, the checked out chain is used.
+
+Variables exported:
+
+  ERIS_CHAIN_NAME, ERIS_CHAIN_ID, ERIS_CHAIN_HOST, ERIS_CHAIN_URL,
+  ERIS_CHAIN_<ENDPOINT>, ERIS_DEFAULT_ACCOUNT, ERIS_KEYS_URL,
+  ERIS_<SERVICE>_URL, ERIS_<SERVICE>_<ENDPOINT>`,
+	Example: `$ eval $(eris env) -- export the variables for the checked out chain
+$ eris env myChain --services ipfs -- also export the ipfs endpoints
+$ eris env myChain --format dotenv > .env -- write a dotenv file
+$ eris env myChain --format json -- display the variables as JSON`,
+	Run: EnvChain,
+}
+
+func buildEnvCommand() {
+	addEnvFlags()
+}
+
+func addEnvFlags() {
+	Env.Flags().StringSliceVarP(&do.ServicesSlice, "services", "s", []string{}, "comma separated list of services to export the endpoints of")
+	Env.Flags().StringVarP(&do.Format, "format", "f", "shell", "output format: shell, dotenv, or json")
+}
+
+func EnvChain(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Help()
+		IfExit(fmt.Errorf("\n**Note** you sent our marmots the wrong number of arguments.\nPlease send the marmots at most 1 argument."))
+	}
+	if len(args) == 1 {
+		do.Name = args[0]
+	}
+	IfExit(chains.EnvChain(do))
+}
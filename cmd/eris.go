@@ -9,6 +9,7 @@ import (
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/initialize"
+	"github.com/eris-ltd/eris-cli/loaders"
 	"github.com/eris-ltd/eris-cli/util"
 	"github.com/eris-ltd/eris-cli/version"
 
@@ -46,6 +47,10 @@ Complete documentation is available at https://docs.erisindustries.com
 			log.SetLevel(log.DebugLevel)
 		}
 
+		if do.Environment != "" {
+			loaders.Environment = do.Environment
+		}
+
 		// Don't try to connect to Docker for informational
 		// or bug fixing commands.
 		switch cmd.Use {
@@ -137,6 +142,8 @@ func AddCommands() {
 	ErisCmd.AddCommand(Data)
 	buildListCommand()
 	ErisCmd.AddCommand(List)
+	buildEnvCommand()
+	ErisCmd.AddCommand(Env)
 	//buildAgentsCommand()
 	//ErisCmd.AddCommand(Agents)
 	buildCleanCommand()
@@ -166,6 +173,7 @@ func AddGlobalFlags() {
 	ErisCmd.PersistentFlags().BoolVarP(&do.Verbose, "verbose", "v", false, "verbose output")
 	ErisCmd.PersistentFlags().BoolVarP(&do.Debug, "debug", "d", false, "debug level output")
 	ErisCmd.PersistentFlags().StringVarP(&do.MachineName, "machine", "m", "eris", "machine name for docker-machine that is running VM")
+	ErisCmd.PersistentFlags().StringVarP(&do.Environment, "environment", "E", "", "merge the NAME.ENVIRONMENT.toml overlays over the definition files (defaults to $ERIS_ENV)")
 }
 
 func InitializeConfig() {
@@ -22,6 +22,10 @@ func buildFlag(cmd *cobra.Command, do *definitions.Do, flag, typ string) { //doe
 		cmd.Flags().BoolVarP(&do.Force, "force", "f", false, "kill the container instantly without waiting to exit") //why do we even have a timeout??
 	case "timeout":
 		cmd.Flags().UintVarP(&do.Timeout, "timeout", "t", 10, "manually set the timeout; overridden by --force")
+	case "wait":
+		cmd.Flags().BoolVarP(&do.Wait, "wait", "", false, fmt.Sprintf("wait for the %s to produce blocks before exiting", typ))
+	case "wait-timeout":
+		cmd.Flags().UintVarP(&do.WaitTimeout, "timeout", "", 60, "number of seconds to --wait for before failing")
 	case "volumes":
 		cmd.Flags().BoolVarP(&do.Volumes, "vol", "o", false, "remove volumes")
 	case "rm-volumes":
@@ -34,6 +38,8 @@ func buildFlag(cmd *cobra.Command, do *definitions.Do, flag, typ string) { //doe
 		cmd.PersistentFlags().BoolVarP(&do.Operations.PublishAllPorts, "publish", "p", false, "publish random ports")
 	case "ports":
 		cmd.PersistentFlags().StringVarP(&do.Operations.Ports, "ports", "", "", "reassign ports")
+	case "auto-ports":
+		cmd.PersistentFlags().BoolVarP(&do.Operations.AutoPorts, "auto-ports", "", false, fmt.Sprintf("publish the %s ports which are already taken on the host to free ports", typ))
 	case "interactive":
 		cmd.Flags().BoolVarP(&do.Operations.Interactive, "interactive", "i", false, "interactive shell")
 	case "pull":
@@ -62,6 +62,7 @@ $ eris ls  -f '{{.ShortName}}\t{{.Info.Config.Env}}'`,
 func buildListCommand() {
 	List.Flags().BoolVarP(&do.All, "all", "a", false, "show extended output")
 	List.Flags().BoolVarP(&do.Running, "running", "r", false, "show only running containers")
+	List.Flags().BoolVarP(&do.Health, "health", "", false, "run the health checks of running containers (otherwise their health is shown as unknown)")
 	List.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
 	List.Flags().StringVarP(&do.Format, "format", "f", "", "alternate format for columnized output")
 }
@@ -74,5 +75,5 @@ func ListAll() {
 		do.Format = "json"
 	}
 
-	IfExit(list.Containers("all", do.Format, do.Running))
+	IfExit(list.Containers("all", do.Format, do.Running, do.Health))
 }
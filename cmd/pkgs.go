@@ -76,8 +76,8 @@ func addPackagesFlags() {
 	packagesDo.Flags().StringVarP(&do.DefaultAddr, "address", "a", "", "default address to use; operates the same way as the [account] job, only before the epm file is ran")
 	packagesDo.Flags().StringVarP(&do.DefaultFee, "fee", "w", "1234", "default fee to use")
 	packagesDo.Flags().StringVarP(&do.DefaultAmount, "amount", "y", "9999", "default amount to use")
-	packagesDo.Flags().StringVarP(&do.ChainPort, "chain-port", "", "46657", "chain rpc port")
-	packagesDo.Flags().StringVarP(&do.KeysPort, "keys-port", "", "4767", "port for keys server")
+	packagesDo.Flags().StringVarP(&do.ChainPort, "chain-port", "", "", "chain rpc port (defaults to the rpc endpoint from the chain definition)")
+	packagesDo.Flags().StringVarP(&do.KeysPort, "keys-port", "", "", "port for keys server (defaults to the api endpoint from the keys definition)")
 	packagesDo.Flags().BoolVarP(&do.Overwrite, "overwrite", "t", true, "overwrite jobs of the same name")
 }
 
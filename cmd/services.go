@@ -30,6 +30,8 @@ image.`,
 func buildServicesCommand() {
 	Services.AddCommand(servicesMake)
 	Services.AddCommand(servicesImport)
+	Services.AddCommand(servicesImportCompose)
+	Services.AddCommand(servicesExportCompose)
 	Services.AddCommand(servicesList)
 	Services.AddCommand(servicesEdit)
 	Services.AddCommand(servicesStart)
@@ -66,9 +68,14 @@ for the -k flag is this
   type Definition struct {
     Name       string       // service name
     Definition string       // definition file name
+    Location   string       // "project", "global", or the ERIS_PATH directory
   }
 
-The -k flag displays the known definition files.`,
+The -k flag displays the known definition files. Definitions are looked up
+in the ./.eris/services project directory, then in the services directory
+of every ERIS_PATH directory, then in ~/.eris/services. The first
+definition found takes precedence and its location is displayed unless
+it is global.`,
 	Run: ListServices,
 	Example: `$ eris services ls -f '{{.ShortName}}\t{{.Info.Config.Cmd}}\t{{.Info.Config.Entrypoint}}'
 $ eris services ls -f '{{.ShortName}}\t{{.Info.Config.Image}}\t{{ports .Info}}'
@@ -77,11 +84,54 @@ $ eris services ls -f '{{.Info.ID}}\t{{.Info.HostConfig.VolumesFrom}}'`,
 }
 
 var servicesImport = &cobra.Command{
-	Use:     "import NAME HASH",
-	Short:   "import a service definition file from IPFS",
-	Long:    `import a service for your platform`,
-	Example: "$ eris services import eth QmQ1LZYPNG4wSb9dojRicWCmM4gFLTPKFUhFnMTR3GKuA2",
-	Run:     ImportService,
+	Use:   "import NAME LOCATION",
+	Short: "import a service definition file from IPFS, Github, or the web",
+	Long: `import a service for your platform
+
+LOCATION can be one of:
+
+  ipfs:HASH                    a file in IPFS (a bare HASH also works)
+  github:ORG/REPO/PATH[@REF]   a file in a GitHub repository (REF is master by default)
+  https://HOST/PATH            a file on a web server
+  file:///PATH                 a file on the host (a bare PATH also works)
+
+The definition file is checked before it is written.`,
+	Example: `$ eris services import eth QmQ1LZYPNG4wSb9dojRicWCmM4gFLTPKFUhFnMTR3GKuA2
+$ eris services import eth github:eris-ltd/eris-services/eth.toml@master
+$ eris services import eth file:///tmp/eth.toml`,
+	Run: ImportService,
+}
+
+var servicesImportCompose = &cobra.Command{
+	Use:   "import-compose LOCATION",
+	Short: "import service definition files from a docker-compose file",
+	Long: `import service definition files from a docker-compose file
+
+Every service of the docker-compose file becomes a service definition
+file in ~/.eris/services. The depends_on, links, and volumes_from keys
+pointing to other services of the file become the dependencies of the
+service. Keys without an eris equivalent (build, networks, labels, etc.)
+are reported and skipped.
+
+LOCATION is a docker-compose file on the host or any other location
+[eris services import] understands.`,
+	Example: `$ eris services import-compose docker-compose.yml
+$ eris services import-compose github:org/repo/docker-compose.yml --force`,
+	Run: ImportComposeServices,
+}
+
+var servicesExportCompose = &cobra.Command{
+	Use:   "export-compose NAME...",
+	Short: "render a docker-compose file from service definitions",
+	Long: `render a docker-compose file from service definitions
+
+The services, the services and chains they depend on, and their data
+containers become services of the docker-compose file. Service
+definition fields without a docker-compose equivalent (endpoints,
+port health checks, etc.) are reported and skipped.`,
+	Example: `$ eris services export-compose ipfs keys
+$ eris services export-compose mysrv --chain simplechain --dest docker-compose.yml`,
+	Run: ExportComposeServices,
 }
 
 var servicesMake = &cobra.Command{
@@ -125,13 +175,16 @@ To stop the service use:      [eris services stop NAME].
 To view a service's logs use: [eris services logs NAME].
 
 You can redefine service ports accessible over the network with
-the --ports flag.
+the --ports flag. If a host port is already taken by another container
+or process, the command fails naming the owner, unless the --auto-ports
+flag is given to publish such ports to free host ports instead.
 `,
 	Run: StartService,
 
 	Example: `$ eris services start ipfs --ports 17000 -- map the first port from the definition file to the host port 17000
 $ eris services start ipfs --ports 17000,18000- -- redefine the first and the second port mappings and autoincrement the rest
-$ eris services start ipfs --ports 50000:5001 -- redefine the specific port mapping (published host port:exposed container port)`,
+$ eris services start ipfs --ports 50000:5001 -- redefine the specific port mapping (published host port:exposed container port)
+$ eris services start ipfs --auto-ports -- publish the ports already taken on the host to free ports`,
 }
 
 var servicesInspect = &cobra.Command{
@@ -149,12 +202,17 @@ $ eris services inspect ipfs host_config.binds -- will display only that value`,
 }
 
 var servicesPorts = &cobra.Command{
-	Use:   "ports NAME [PORT]...",
-	Short: "print port mappings",
-	Long: `print port mappings
-
-The [eris services ports] command displays published service ports.`,
-	Example: `$ eris services ports ipfs -- will display all IPFS ports
+	Use:   "ports NAME [ENDPOINT|PORT]...",
+	Short: "print service endpoints and port mappings",
+	Long: `print service endpoints and port mappings
+
+The [eris services ports] command displays the named service endpoints
+(rpc, p2p, api, or the names from the [service.endpoints] table of the
+service definition file), the container ports they are exposed at,
+the host ports they are published to, and the addresses they are
+reachable at.`,
+	Example: `$ eris services ports ipfs -- will display all IPFS endpoints
+$ eris services ports keys api -- will display the address of the keys api endpoint
 $ eris services ports ipfs 4001 5001 -- will display specific IPFS ports`,
 	Run: PortsService,
 }
@@ -230,11 +288,23 @@ var servicesCat = &cobra.Command{
 	Short: "display the service definition file",
 	Long: `display the service definition file
 
-Command will cat local service definition file.`,
+Command will cat local service definition file. With the --resolved
+flag, the definitions it extends and its environment overlay (see the
+global --environment flag) are merged in and its variables are resolved.
+With the --layers flag, every value is listed with the file it came from.`,
 	Run: CatService,
 }
 
 func addServicesFlags() {
+	servicesImportCompose.Flags().BoolVarP(&do.Force, "force", "f", false, "overwrite the existing service definition files")
+
+	servicesExportCompose.Flags().StringVarP(&do.ChainName, "chain", "c", "", "specify a chain the services depend on")
+	servicesExportCompose.Flags().StringVarP(&do.Destination, "dest", "", "", "write the docker-compose file to a file instead of displaying it")
+
+	servicesCat.Flags().BoolVarP(&do.Resolved, "resolved", "", false, "display the service definition merged with the definitions it extends")
+	servicesCat.Flags().BoolVarP(&do.Layers, "layers", "", false, "display which definition file (base, extended, or environment overlay) each value came from")
+	servicesCat.Flags().StringVarP(&do.ChainName, "chain", "c", "", "resolve the ${chain} variables with this chain (with --resolved)")
+
 	buildFlag(servicesLogs, do, "follow", "service")
 	buildFlag(servicesLogs, do, "tail", "service")
 
@@ -258,6 +328,7 @@ func addServicesFlags() {
 
 	buildFlag(servicesStart, do, "publish", "service")
 	buildFlag(servicesStart, do, "ports", "service")
+	buildFlag(servicesStart, do, "auto-ports", "service")
 	buildFlag(servicesStart, do, "env", "service")
 	buildFlag(servicesStart, do, "links", "service")
 	servicesStart.Flags().StringVarP(&do.ChainName, "chain", "c", "", "specify a chain the service depends on")
@@ -274,6 +345,7 @@ func addServicesFlags() {
 	servicesList.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
 	servicesList.Flags().BoolVarP(&do.All, "all", "a", false, "show extended output")
 	servicesList.Flags().BoolVarP(&do.Running, "running", "r", false, "show running containers only")
+	servicesList.Flags().BoolVarP(&do.Health, "health", "", false, "run the health checks of running containers (otherwise their health is shown as unknown)")
 	servicesList.Flags().BoolVarP(&do.Quiet, "quiet", "q", false, "show a list of service names")
 	servicesList.Flags().StringVarP(&do.Format, "format", "f", "", "alternate format for columnized output")
 }
@@ -320,10 +392,22 @@ func KillService(cmd *cobra.Command, args []string) {
 func ImportService(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(2, "ge", cmd, args))
 	do.Name = args[0]
-	do.Hash = args[1]
+	do.Path = args[1]
 	IfExit(srv.ImportService(do))
 }
 
+func ImportComposeServices(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Path = args[0]
+	IfExit(srv.ImportCompose(do))
+}
+
+func ExportComposeServices(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "ge", cmd, args))
+	do.Operations.Args = args
+	IfExit(srv.ExportCompose(do))
+}
+
 func MakeService(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(2, "ge", cmd, args))
 	do.Name = args[0]
@@ -389,7 +473,7 @@ func ListServices(cmd *cobra.Command, args []string) {
 	if do.Known {
 		IfExit(list.Known("services", do.Format))
 	} else {
-		IfExit(list.Containers(def.TypeService, do.Format, do.Running))
+		IfExit(list.Containers(def.TypeService, do.Format, do.Running, do.Health))
 	}
 }
 
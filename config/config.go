@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 )
 
 // Properly scope the globalConfig.
@@ -70,10 +72,63 @@ func SetGlobalObject(writer, errorWriter io.Writer) (*ErisCli, error) {
 	return &e, nil
 }
 
+// ProjectDirectory is the directory in the working directory which holds
+// the project-local services, chains, and actions definitions.
+const ProjectDirectory = ".eris"
+
+// DefinitionPaths returns the directories the definitions of the globalDir
+// definitions directory (dir.ServicesPath, dir.ChainsPath, or dir.ActionsPath)
+// are looked up in, in order of precedence:
+//
+//  ./.eris/TYPE     - the project directory (see ProjectDirectory)
+//  ROOT/TYPE        - for every ROOT directory in the ERIS_PATH environment variable
+//  ~/.eris/TYPE     - the globalDir directory
+//
+// Only existing directories are returned besides the global one. Other
+// directories are returned as is.
+func DefinitionPaths(globalDir string) []string {
+	switch globalDir {
+	case dir.ServicesPath, dir.ChainsPath, dir.ActionsPath:
+	default:
+		return []string{globalDir}
+	}
+
+	typ := filepath.Base(globalDir)
+	roots := []string{ProjectDirectory}
+	roots = append(roots, filepath.SplitList(os.Getenv("ERIS_PATH"))...)
+
+	var (
+		paths []string
+		seen  = make(map[string]bool)
+	)
+	global, _ := filepath.Abs(globalDir)
+	seen[global] = true
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		path, err := filepath.Abs(filepath.Join(root, typ))
+		if err != nil || seen[path] {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return append(paths, globalDir)
+}
+
+// LoadViperConfig reads the configName config file from the configPath
+// directory. Definitions of the global definitions directories are
+// looked up in the DefinitionPaths directories.
 func LoadViperConfig(configPath, configName string) (*viper.Viper, error) {
 	var conf = viper.New()
 
-	conf.AddConfigPath(configPath)
+	for _, path := range DefinitionPaths(configPath) {
+		conf.AddConfigPath(path)
+	}
 	conf.SetConfigName(configName)
 	err := conf.ReadInConfig()
 	if err != nil {
@@ -94,6 +149,75 @@ List available definitions with the [eris %s ls --known] command
 	return conf, nil
 }
 
+// RenameDefinitionFile writes the definition config as it was read
+// (without resolving the definitions it extends, its overlays, or its
+// variables) to the fileName file with the name and service.name values
+// set to name. The file format follows the fileName extension (TOML by
+// default).
+func RenameDefinitionFile(definition *viper.Viper, name, fileName string) error {
+	settings := stringKeys(definition.AllSettings()).(map[string]interface{})
+	settings["name"] = name
+	if service, ok := settings["service"].(map[string]interface{}); ok {
+		if _, ok := service["name"]; ok {
+			service["name"] = name
+		}
+	}
+
+	writer, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	switch filepath.Ext(fileName) {
+	case ".json":
+		mar, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(append(mar, '\n'))
+		return err
+	case ".yaml":
+		mar, err := yaml.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(mar)
+		return err
+	default:
+		writer.Write([]byte("# This is a TOML config file.\n# For more information, see https://github.com/toml-lang/toml\n\n"))
+		enc := toml.NewEncoder(writer)
+		enc.Indent = ""
+		return enc.Encode(settings)
+	}
+}
+
+// stringKeys converts the map[interface{}]interface{} tables of
+// YAML configs to map[string]interface{} ones.
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, value := range v {
+			m[fmt.Sprint(key)] = stringKeys(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for key, value := range v {
+			m[key] = stringKeys(value)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, value := range v {
+			list[i] = stringKeys(value)
+		}
+		return list
+	}
+	return value
+}
+
 func LoadGlobalConfig() (*viper.Viper, error) {
 	globalConfig, err := SetDefaults()
 	if err != nil {
@@ -5,10 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	ver "github.com/eris-ltd/eris-cli/version"
 
+	dir "github.com/eris-ltd/common/go/common"
 	log "github.com/eris-ltd/eris-logger"
 )
 
@@ -685,6 +687,118 @@ func removeErisDir() {
 	}
 }
 
+func TestDefinitionPaths(t *testing.T) {
+	root := filepath.Join(configErisDir, "paths")
+	defer os.RemoveAll(root)
+
+	global := filepath.Join(root, "global", "services")
+	project := filepath.Join(root, "project")
+	shared := filepath.Join(root, "shared")
+	for _, path := range []string{global, filepath.Join(project, ProjectDirectory, "services"), filepath.Join(shared, "services")} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("cannot create a directory: %v", err)
+		}
+	}
+	if err := fakeDefinitionFile(global, "test", `image = "global"`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if err := fakeDefinitionFile(filepath.Join(shared, "services"), "test", `image = "shared"`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	savedPath, savedErisPath := dir.ServicesPath, os.Getenv("ERIS_PATH")
+	wd, _ := os.Getwd()
+	defer func() {
+		dir.ServicesPath = savedPath
+		os.Setenv("ERIS_PATH", savedErisPath)
+		os.Chdir(wd)
+	}()
+	dir.ServicesPath = global
+	os.Setenv("ERIS_PATH", strings.Join([]string{filepath.Join(root, "missing"), shared}, string(filepath.ListSeparator)))
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("cannot change the directory: %v", err)
+	}
+
+	// The temporary directory path may contain symbolic links.
+	projectServices, _ := filepath.Abs(filepath.Join(ProjectDirectory, "services"))
+	expected := []string{projectServices, filepath.Join(shared, "services"), global}
+	if paths := DefinitionPaths(global); !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected paths %v, got %v", expected, paths)
+	}
+	if paths := DefinitionPaths(root); !reflect.DeepEqual(paths, []string{root}) {
+		t.Fatalf("expected only the %s path, got %v", root, paths)
+	}
+
+	conf, err := LoadViperConfig(global, "test")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+	if conf.GetString("image") != "shared" {
+		t.Fatalf("expected the ERIS_PATH definition to take precedence, got %q", conf.GetString("image"))
+	}
+
+	if err := fakeDefinitionFile(projectServices, "test", `image = "project"`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if conf, err = LoadViperConfig(global, "test"); err != nil || conf.GetString("image") != "project" {
+		t.Fatalf("expected the project definition to take precedence, got %v", err)
+	}
+}
+
+func TestRenameDefinitionFile(t *testing.T) {
+	root := filepath.Join(configErisDir, "rename")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatalf("cannot create a directory: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := fakeDefinitionFile(root, "old", `
+name = "old"
+extends = "base"
+chain = "${chain}"
+
+[service]
+name = "old"
+image = "${ERIS_TEST_IMAGE}"
+ports = [ "4767" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	definition, err := LoadViperConfig(root, "old")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+
+	for _, ext := range []string{".toml", ".json", ".yaml"} {
+		if err := RenameDefinitionFile(definition, "new", filepath.Join(root, "new"+ext)); err != nil {
+			t.Fatalf("expected the %s definition to be written, got %v", ext, err)
+		}
+
+		renamed, err := LoadViperConfig(root, "new")
+		if err != nil {
+			t.Fatalf("expected the %s definition to load, got %v", ext, err)
+		}
+		for key, expected := range map[string]interface{}{
+			"name":          "new",
+			"extends":       "base",
+			"chain":         "${chain}",
+			"service.name":  "new",
+			"service.image": "${ERIS_TEST_IMAGE}",
+			"service.ports": []string{"4767"},
+		} {
+			var returned interface{} = renamed.GetString(key)
+			if _, ok := expected.([]string); ok {
+				returned = renamed.GetStringSlice(key)
+			}
+			if !reflect.DeepEqual(expected, returned) {
+				t.Fatalf("expected the %s definition %s = %v, got %v", ext, key, expected, returned)
+			}
+		}
+		os.Remove(filepath.Join(root, "new"+ext))
+	}
+}
+
 func fakeDefinitionFile(tmpDir, name, definition string) error {
 	filename := filepath.Join(tmpDir, name+".toml")
 	out, err := os.Create(filename)
@@ -11,7 +11,8 @@ type Action struct {
 	// be passed in via a command line flag
 	Chain string `json:"chain" yaml:"chain" toml:"chain"`
 	// an array of strings which should be ran in a sequence of subshells
-	Steps []string `json:"steps" yaml:"steps" toml:"steps"`
+	// (only the built-in ${...} variables are resolved, see loaders.Variables)
+	Steps []string `json:"steps" yaml:"steps" toml:"steps" interpolate:"shell"`
 	// environment variables to give the subshells
 	Environment map[string]string `json:"environment" yaml:"environment" toml:"environment"`
 
@@ -30,7 +30,9 @@ func EPMApp() *AppType {
 	app := BlankAppType()
 	app.Name = "epm"
 	app.BaseImage = path.Join(version.ERIS_REG_DEF, version.ERIS_IMG_PM)
-	app.EntryPoint = "epm --chain chain:46657 --sign keys:4767"
+	// The --chain and --sign flags are added from the chain and keys
+	// endpoints when the app is run (see pkgs.DefinePkgActionService).
+	app.EntryPoint = "epm"
 	app.DeployCmd = ""
 	app.TestCmd = ""
 	app.ChainTypes = []string{"mint"}
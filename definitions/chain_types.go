@@ -0,0 +1,28 @@
+package definitions
+
+// AccountType describes a kind of accounts [eris chains make] creates,
+// e.g. "Full" or "Participant". Account types are read from the
+// account-types directory (common.AccountsTypePath).
+type AccountType struct {
+	Name        string `mapstructure:"name" json:"name" yaml:"name" toml:"name"`
+	Definition  string `mapstructure:"definition" json:"definition,omitempty" yaml:"definition,omitempty" toml:"definition,omitempty"`
+	TypicalUser string `mapstructure:"typical_user" json:"typical_user,omitempty" yaml:"typical_user,omitempty" toml:"typical_user,omitempty"`
+	// number of accounts made if the number isn't given
+	DefaultNumber int `mapstructure:"default_number" json:"default_number" yaml:"default_number" toml:"default_number"`
+	// initial balance of every account
+	DefaultTokens int64 `mapstructure:"default_tokens" json:"default_tokens" yaml:"default_tokens" toml:"default_tokens"`
+	// validator bond of every account (accounts with no bond aren't validators)
+	DefaultBond int64 `mapstructure:"default_bond" json:"default_bond" yaml:"default_bond" toml:"default_bond"`
+	// permission name (e.g. "call", see chains.PermissionFlags) => 0 or 1
+	Perms map[string]int `mapstructure:"perms" json:"perms" yaml:"perms" toml:"perms"`
+}
+
+// ChainType describes a typical chain as a number of accounts of every
+// account type, e.g. "simplechain" is one "Full" account. Chain types
+// are read from the chain-types directory (common.ChainTypePath).
+type ChainType struct {
+	Name       string `mapstructure:"name" json:"name" yaml:"name" toml:"name"`
+	Definition string `mapstructure:"definition" json:"definition,omitempty" yaml:"definition,omitempty" toml:"definition,omitempty"`
+	// account type name => number of accounts
+	AccountTypes map[string]int `mapstructure:"account_types" json:"account_types" yaml:"account_types" toml:"account_types"`
+}
@@ -7,6 +7,8 @@ type Chain struct {
 	ChainID string `mapstructure:"chain_id" json:"chain_id" yaml:"chain_id" toml:"chain_id"`
 	// type of the chain
 	ChainType string `mapstructure:"chain_type" json:"chain_type" yaml:"chain_type" toml:"chain_type"`
+	// name of the cluster the chain is a node of (see `eris chains new --nodes`)
+	Cluster string `mapstructure:"cluster" json:"cluster,omitempty" yaml:"cluster,omitempty" toml:"cluster,omitempty"`
 
 	// same fields as in the Service Struct/Service Specification
 	Service      *Service      `json:"service,omitempty" yaml:"service,omitempty" toml:"service,omitempty"`
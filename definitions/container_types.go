@@ -14,6 +14,10 @@ const (
 	LabelTest      = Namespace + ":" + "TEST"
 	LabelTestID    = Namespace + ":" + "TEST_ID"
 
+	LabelHealthCheck = Namespace + ":" + "HEALTHCHECK"
+	LabelCluster     = Namespace + ":" + "CLUSTER"
+	LabelPorts       = Namespace + ":" + "PORTS"
+
 	TypeChain   = "chain"
 	TypeService = "service"
 	TypeData    = "data"
@@ -27,6 +27,8 @@ type Do struct {
 	OutputTable   bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Overwrite     bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Dump          bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Resolved      bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Layers        bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Lines         int      `mapstructure:"," json:"," yaml:"," toml:","` // XXX: for tail and logs
 	Timeout       uint     `mapstructure:"," json:"," yaml:"," toml:","`
 	N             uint     `mapstructure:"," json:"," yaml:"," toml:","`
@@ -44,6 +46,7 @@ type Do struct {
 	Hash          string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Gateway       string   `mapstructure:"," json:"," yaml:"," toml:","`
 	MachineName   string   `mapstructure:"," json:"," yaml:"," toml:","`
+	Environment   string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Name          string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Image         string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Path          string   `mapstructure:"," json:"," yaml:"," toml:","`
@@ -82,6 +85,31 @@ type Do struct {
 	Uninstall  bool `mapstructure:"," json:"," yaml:"," toml:","`
 	Volumes    bool `mapstructure:"," json:"," yaml:"," toml:","`
 
+	//chains clone
+	NewKeys bool `mapstructure:"," json:"," yaml:"," toml:","`
+
+	//chains checkout
+	Local bool `mapstructure:"," json:"," yaml:"," toml:","`
+
+	//chains start/new --wait
+	Wait        bool `mapstructure:"," json:"," yaml:"," toml:","`
+	WaitTimeout uint `mapstructure:"," json:"," yaml:"," toml:","`
+
+	//chains types new
+	Definition    string   `mapstructure:"," json:"," yaml:"," toml:","`
+	DefaultNumber int      `mapstructure:"," json:"," yaml:"," toml:","`
+	Tokens        int64    `mapstructure:"," json:"," yaml:"," toml:","`
+	Bond          int64    `mapstructure:"," json:"," yaml:"," toml:","`
+	Perms         []string `mapstructure:"," json:"," yaml:"," toml:","`
+
+	//chains join
+	Seeds       []string `mapstructure:"," json:"," yaml:"," toml:","`
+	GenesisHash string   `mapstructure:"," json:"," yaml:"," toml:","`
+	Validator   bool     `mapstructure:"," json:"," yaml:"," toml:","`
+
+	//chains events
+	Filter []string `mapstructure:"," json:"," yaml:"," toml:","`
+
 	//data import/export
 	Source      string `mapstructure:"," json:"," yaml:"," toml:","`
 	Destination string `mapstructure:"," json:"," yaml:"," toml:","`
@@ -89,6 +117,7 @@ type Do struct {
 	//listing functions
 	Known     bool `mapstructure:"," json:"," yaml:"," toml:","`
 	Running   bool `mapstructure:"," json:"," yaml:"," toml:","`
+	Health    bool `mapstructure:"," json:"," yaml:"," toml:","`
 	Existing  bool `mapstructure:"," json:"," yaml:"," toml:","`
 	Host      bool `mapstructure:"," json:"," yaml:"," toml:","` //keys ls
 	Container bool `mapstructure:"," json:"," yaml:"," toml:","` //keys ls
@@ -0,0 +1,17 @@
+package definitions
+
+// Endpoint names.
+const (
+	EndpointRPC = "rpc" // chain RPC server (status, blocks, transactions)
+	EndpointP2P = "p2p" // chain peer to peer connections
+	EndpointAPI = "api" // eris:db API or the service API (e.g. eris-keys)
+)
+
+// WellKnownEndpoints names the container ports of Eris images, so that
+// chain and service definitions don't have to list them in [endpoints].
+var WellKnownEndpoints = map[string]string{
+	"46657": EndpointRPC,
+	"46656": EndpointP2P,
+	"1337":  EndpointAPI,
+	"4767":  EndpointAPI,
+}
@@ -0,0 +1,29 @@
+package definitions
+
+// HealthCheck describes how to tell if a service or a chain container
+// is ready to accept connections. Only one kind of check is performed:
+// an HTTP GET request (if HTTP is set), a TCP connection to Port, or
+// the Exec command run inside the container.
+type HealthCheck struct {
+	// container port to check, e.g. "46657" or "46657/tcp"
+	Port string `json:"port,omitempty" yaml:"port,omitempty" toml:"port,omitempty"`
+	// path for an HTTP GET request to Port, e.g. "/status"
+	HTTP string `json:"http,omitempty" yaml:"http,omitempty" toml:"http,omitempty"`
+	// command to run inside the container (healthy if it exits with 0)
+	Exec string `json:"exec,omitempty" yaml:"exec,omitempty" toml:"exec,omitempty" interpolate:"shell"`
+	// time between checks, e.g. "2s" (1s by default)
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty"`
+	// time given to a single check, e.g. "500ms" (1s by default)
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	// number of failed checks before the container is considered unhealthy (30 by default)
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"`
+}
+
+// Health states of a container.
+const (
+	HealthNone      = ""
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+	HealthUnknown   = "unknown"
+)
@@ -10,11 +10,13 @@ type Service struct {
 	// restart policy: "always" or "max:<#attempts>"
 	Restart string `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	// maps directly to docker cmd
-	Command string `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	Command string `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty" interpolate:"shell"`
 	// maps directly to docker links
 	Links []string `mapstructure:"links" json:"links,omitempty" yaml:"links,omitempty" toml:"links,omitempty"`
 	// maps directly to docker ports
 	Ports []string `mapstructure:"ports" json:"ports,omitempty" yaml:"ports,omitempty" toml:"ports,omitempty"`
+	// names of the container ports, e.g. rpc = "46657" (see WellKnownEndpoints)
+	Endpoints map[string]string `mapstructure:"endpoints" json:"endpoints,omitempty" yaml:"endpoints,omitempty" toml:"endpoints,omitempty"`
 	// maps directly do docker expose
 	Expose []string `mapstructure:"expose" json:"expose,omitempty" yaml:"expose,omitempty" toml:"expose,omitempty"`
 	// maps directly to docker volumes
@@ -36,7 +38,7 @@ type Service struct {
 	// maps directly to docker workdir
 	WorkDir string `mapstructure:"work_dir" json:"work_dir,omitempty" yaml:"work_dir,omitempty" toml:"work_dir,omitempty"`
 	// maps directly to docker entrypoint
-	EntryPoint string `mapstructure:"entry_point" json:"entry_point,omitempty" yaml:"entry_point,omitempty" toml:"entry_point,omitempty"`
+	EntryPoint string `mapstructure:"entry_point" json:"entry_point,omitempty" yaml:"entry_point,omitempty" toml:"entry_point,omitempty" interpolate:"shell"`
 	// maps directly to docker hostname
 	HostName string `mapstructure:"host_name" json:"host_name,omitempty" yaml:"host_name,omitempty" toml:"host_name,omitempty"`
 	// maps directly to docker domainname
@@ -48,6 +50,9 @@ type Service struct {
 	// maps directly to docker mem_limit
 	MemLimit int64 `mapstructure:"mem_limit" json:"memory,omitempty,omitzero" yaml:"memory,omitempty" toml:"memory,omitempty,omitzero"`
 
+	// readiness check used before starting dependent containers
+	HealthCheck *HealthCheck `mapstructure:"healthcheck" json:"healthcheck,omitempty" yaml:"healthcheck,omitempty" toml:"healthcheck,omitempty"`
+
 	// an env variable to set for when we are running `eris exec` so we can find the main container
 	ExecHost string `mapstructure:"exec_host" json:"memory,omitempty,omitzero" yaml:"memory,omitempty" toml:"memory,omitempty,omitzero"`
 }
@@ -20,12 +20,12 @@ import (
 
 const (
 	// `eris ls` format.
-	standardTmplHeader = "{{toupper .}}\tON\tCONTAINER ID\tDATA CONTAINER"
-	standardTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}"
+	standardTmplHeader = "{{toupper .}}\tON\tHEALTH\tCONTAINER ID\tDATA CONTAINER"
+	standardTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{health .Info}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}"
 
 	// `eris ls -a` format.
-	extendedTmplHeader = "{{toupper .}}\tON\tCONTAINER ID\tDATA CONTAINER\tIMAGE\tCOMMAND\tPORTS"
-	extendedTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}\t{{.Info.Config.Image}}\t{{.Info.Config.Cmd}}\t{{ports .Info}}"
+	extendedTmplHeader = "{{toupper .}}\tON\tHEALTH\tCONTAINER ID\tDATA CONTAINER\tIMAGE\tCOMMAND\tPORTS"
+	extendedTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{health .Info}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}\t{{.Info.Config.Image}}\t{{.Info.Config.Cmd}}\t{{ports .Info}}"
 
 	// Data section.
 	dataTmplHeader = "{{toupper .}}\tON\tCONTAINER ID"
@@ -35,6 +35,9 @@ const (
 var (
 	erisContainers = []*util.Details{}
 
+	// Perform the health checks of running containers.
+	liveHealth bool
+
 	// Template helpers to manipulate raw field values in the output.
 	helpers = map[string]interface{}{
 		"toupper": func(word string) string {
@@ -68,6 +71,14 @@ var (
 			}
 			return ""
 		},
+		// Show the health state of a container with a health
+		// check or a '-' symbol otherwise.
+		"health": func(container *docker.Container) string {
+			if health := util.ContainerHealth(container, liveHealth); health != def.HealthNone {
+				return health
+			}
+			return "-"
+		},
 		// Pretty-format Docker ports.
 		"ports": func(container *docker.Container) string {
 			return util.FormulatePortsOutput(container)
@@ -79,13 +90,18 @@ var (
 // specified by the "format" parameter: the default "" and "extended" use the
 // predefined Go templates, "json" dumps the JSON document of container
 // details for every container. A custom format can be specified using
-// the Go template syntax.
-func Containers(t, format string, running bool) error {
+// the Go template syntax. If health is true, the health checks of
+// running containers are performed to show their health state, which
+// can take up to the health check timeout for every container.
+func Containers(t, format string, running, health bool) error {
 	log.WithFields(log.Fields{
 		"format": format,
 		"type":   t,
+		"health": health,
 	}).Debug("Listing containers")
 
+	liveHealth = health
+
 	// Dump a JSON document then terminate.
 	if format == "json" {
 		return jsonContainers(t, running)
@@ -99,6 +115,7 @@ func Containers(t, format string, running bool) error {
 		erisContainers = append(erisContainers, details)
 		return true
 	}, false)
+	erisContainers = groupClusters(erisContainers)
 
 	// Keys for the parameter map.
 	const (
@@ -178,6 +195,38 @@ func Containers(t, format string, running bool) error {
 	return nil
 }
 
+// groupClusters replaces the containers of chain cluster nodes with
+// a single entry named after the cluster. The cluster is shown as running
+// only if all its nodes are running.
+func groupClusters(containers []*util.Details) []*util.Details {
+	var grouped []*util.Details
+
+	clusters := make(map[string]*util.Details)
+	for _, container := range containers {
+		cluster := container.Labels[def.LabelCluster]
+		if container.Type != def.TypeChain || cluster == "" {
+			grouped = append(grouped, container)
+			continue
+		}
+
+		if c, ok := clusters[cluster]; ok {
+			c.Info.State.Running = c.Info.State.Running && container.Info.State.Running
+			continue
+		}
+
+		info := *container.Info
+		clusters[cluster] = &util.Details{
+			Type:      container.Type,
+			ShortName: cluster,
+			FullName:  container.FullName,
+			Labels:    container.Labels,
+			Info:      &info,
+		}
+		grouped = append(grouped, clusters[cluster])
+	}
+	return grouped
+}
+
 func isOrphanDataContainers() bool {
 	for _, container := range erisContainers {
 		if container.Type == def.TypeData {
@@ -11,21 +11,28 @@ import (
 	"text/tabwriter"
 	"text/template"
 
+	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/util"
 
+	"github.com/eris-ltd/common/go/common"
+
 	"github.com/docker/docker/pkg/term"
 	"github.com/kr/text/colwriter"
 )
 
 // Definition holds useful data about definition files
-// (the definition name and full path to the file).
+// (the definition name, full path to the file, and where
+// it was found, see config.DefinitionPaths).
 type Definition struct {
 	Name       string
 	Definition string
+	// "project", "global", or the ERIS_PATH directory
+	Location string
 }
 
 // Known list definition files for a given type t ("chains", "services",
-// or "actions") from the Eris root directory in one of the 3 formats,
+// or "actions") from the project, ERIS_PATH, and Eris root directories
+// (see config.DefinitionPaths) in one of the 3 formats,
 // specified by the format parameter. Default is `ls(1)` multicolumn format,
 // `json` dumps the JSON document onto the console. A custom format can
 // be specified using the `text/template` Go package syntax, e.g.:
@@ -40,6 +47,7 @@ func Known(t, format string) error {
 		definitions = append(definitions, Definition{
 			Name:       strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
 			Definition: file,
+			Location:   definitionLocation(file),
 		})
 	}
 
@@ -77,7 +85,11 @@ func columnizeKnown(definitions []Definition) error {
 	for _, definition := range definitions {
 		// Extra space to match the standard `ls` multicolumn output
 		// which uses 2 spaces between file names.
-		fmt.Fprintln(w, filepath.Base(definition.Definition)+" ")
+		name := filepath.Base(definition.Definition)
+		if definition.Location != "global" {
+			name += " (" + definition.Location + ")"
+		}
+		fmt.Fprintln(w, name+" ")
 	}
 	w.Flush()
 
@@ -106,3 +118,19 @@ func customKnown(definitions []Definition, format string) error {
 
 	return nil
 }
+
+// definitionLocation returns "project" for the definition files found in
+// the project directory, the ERIS_PATH directory for the files found there,
+// and "global" for the rest.
+func definitionLocation(file string) string {
+	dir := filepath.Dir(file)
+	switch dir {
+	case common.ServicesPath, common.ChainsPath, common.ActionsPath, common.ChainTypePath, common.AccountsTypePath:
+		return "global"
+	}
+
+	if project, err := filepath.Abs(config.ProjectDirectory); err == nil && filepath.Dir(dir) == project {
+		return "project"
+	}
+	return filepath.Dir(dir)
+}
@@ -0,0 +1,115 @@
+package loaders
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+// LoadAccountTypes reads all the account type definition files from
+// the common.AccountsTypePath directory and returns them sorted by name.
+func LoadAccountTypes() ([]*definitions.AccountType, error) {
+	var types []*definitions.AccountType
+	err := loadTypes(common.AccountsTypePath, func(name string) interface{} {
+		accountType := &definitions.AccountType{Name: name}
+		types = append(types, accountType)
+		return accountType
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(accountTypesByName(types))
+	return types, nil
+}
+
+// LoadAccountType returns the account type definition by its name,
+// ignoring the case ("full" is the same as "Full").
+func LoadAccountType(name string) (*definitions.AccountType, error) {
+	types, err := LoadAccountTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, accountType := range types {
+		if strings.EqualFold(accountType.Name, name) {
+			return accountType, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown account type %q. Please see the account types in %s", name, common.AccountsTypePath)
+}
+
+// LoadChainTypes reads all the chain type definition files from
+// the common.ChainTypePath directory and returns them sorted by name.
+func LoadChainTypes() ([]*definitions.ChainType, error) {
+	var types []*definitions.ChainType
+	err := loadTypes(common.ChainTypePath, func(name string) interface{} {
+		chainType := &definitions.ChainType{Name: name}
+		types = append(types, chainType)
+		return chainType
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(chainTypesByName(types))
+	return types, nil
+}
+
+// LoadChainType returns the chain type definition by its name,
+// ignoring the case.
+func LoadChainType(name string) (*definitions.ChainType, error) {
+	types, err := LoadChainTypes()
+	if err != nil {
+		return nil, err
+	}
+	for _, chainType := range types {
+		if strings.EqualFold(chainType.Name, name) {
+			return chainType, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown chain type %q. Please see the chain types in %s", name, common.ChainTypePath)
+}
+
+// loadTypes reads every TOML file in the dir into the structure
+// returned by blank for the file name (without the extension).
+func loadTypes(dir string, blank func(name string) interface{}) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("Cannot read the %s directory: %v", dir, err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".toml" {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), ".toml")
+
+		definition, err := config.LoadViperConfig(dir, name)
+		if err != nil {
+			return err
+		}
+		if err := definition.Unmarshal(blank(name)); err != nil {
+			return fmt.Errorf("Cannot read %s: %v", file.Name(), err)
+		}
+	}
+	return nil
+}
+
+type accountTypesByName []*definitions.AccountType
+
+func (t accountTypesByName) Len() int           { return len(t) }
+func (t accountTypesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t accountTypesByName) Less(i, j int) bool { return t[i].Name < t[j].Name }
+
+type chainTypesByName []*definitions.ChainType
+
+func (t chainTypesByName) Len() int           { return len(t) }
+func (t chainTypesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t chainTypesByName) Less(i, j int) bool { return t[i].Name < t[j].Name }
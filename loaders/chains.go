@@ -26,8 +26,9 @@ const (
 )
 
 // LoadChainDefinition reads the "default" then chainName definition files
-// from the common.ChainsPath directory and returns a chain structure set
-// accordingly. LoadChainDefinition also returns missing files or definition
+// (preceded by the definitions it extends and followed by its environment
+// overlay, see DefinitionLayers) from the common.ChainsPath directory and
+// returns a chain structure set accordingly. LoadChainDefinition also returns missing files or definition
 // reading errors, if any.
 func LoadChainDefinition(chainName string) (*definitions.Chain, error) {
 	chain := definitions.BlankChain()
@@ -43,11 +44,28 @@ func LoadChainDefinition(chainName string) (*definitions.Chain, error) {
 		return nil, err
 	}
 
+	levels, err := DefinitionLayers(common.ChainsPath, chainName, definition)
+	if err != nil {
+		return nil, err
+	}
+
 	// Overwrite chain.ChainID and chain.Service according from
-	// the definition.
-	if err = MarshalChainDefinition(definition, chain); err != nil {
+	// the definition, the definitions it extends, and its overlay.
+	merged, err := mergeChainLevels(definition, levels)
+	if err != nil {
 		return nil, err
 	}
+	overwriteChain(chain, merged)
+
+	if err = Interpolate(chain, chainName); err != nil {
+		return nil, fmt.Errorf("Cannot load the %s chain definition: %v", chainName, err)
+	}
+
+	// Cluster nodes are labeled, so that they can be
+	// listed and operated on as one unit.
+	if chain.Cluster != "" {
+		chain.Operations.Labels = util.SetLabel(chain.Operations.Labels, definitions.LabelCluster, chain.Cluster)
+	}
 
 	if chain.Dependencies != nil {
 		addDependencyVolumesAndLinks(chain.Dependencies, chain.Service, chain.Operations)
@@ -78,10 +96,22 @@ func ChainsAsAService(chainName string) (*definitions.ServiceDefinition, error)
 	chain.Service.AutoData = true
 	chain.Service.Command = ErisChainStart
 
+	// Chains always depend on keys, in addition to the dependencies
+	// from the chain definition.
+	deps := &definitions.Dependencies{Services: []string{"keys"}}
+	if chain.Dependencies != nil {
+		for _, name := range chain.Dependencies.Services {
+			if name != "keys" {
+				deps.Services = append(deps.Services, name)
+			}
+		}
+		deps.Chains = chain.Dependencies.Chains
+	}
+
 	s := &definitions.ServiceDefinition{
 		Name:         chain.Name,
 		ServiceID:    chain.ChainID,
-		Dependencies: &definitions.Dependencies{Services: []string{"keys"}},
+		Dependencies: deps,
 		Service:      chain.Service,
 		Operations:   chain.Operations,
 		Maintainer:   chain.Maintainer,
@@ -125,17 +155,49 @@ func MockChainDefinition(chainName, chainID string) *definitions.Chain {
 // and chain.Service fields in the chain structure. Returns config read errors.
 func MarshalChainDefinition(definition *viper.Viper, chain *definitions.Chain) error {
 	log.Debug("Marshalling chain")
-	chnTemp := definitions.BlankChain()
-	err := definition.Unmarshal(chnTemp)
+	chnTemp, err := unmarshalChainLevel(definition)
 	if err != nil {
-		return fmt.Errorf("The marmots coult not read the chain definition: %v", err)
+		return err
 	}
 
-	util.Merge(chain.Service, chnTemp.Service)
-	if len(chnTemp.Service.Ports) != 0 {
-		chain.Service.Ports = chnTemp.Service.Ports
+	overwriteChain(chain, chnTemp)
+	return nil
+}
+
+// mergeChainLevels merges the chain definition levels (see DefinitionLayers)
+// the way ResolveServiceDefinition merges service ones (see util.DeepMerge):
+// lists are appended (the overlay lists replace them, see util.DeepOverlay)
+// and blank values don't unset the values of the levels before. The names
+// of the levels other than the definition itself are not inherited.
+func mergeChainLevels(definition *viper.Viper, levels []*viper.Viper) (*definitions.Chain, error) {
+	merge := util.DeepMerge
+	chain := definitions.BlankChain()
+	for _, level := range levels {
+		levelChain, err := unmarshalChainLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		if level != definition {
+			levelChain.Name = ""
+			levelChain.Service.Name = ""
+		}
+		if err = merge(chain, levelChain); err != nil {
+			return nil, err
+		}
+		if level == definition {
+			merge = util.DeepOverlay
+		}
 	}
-	chain.ChainID = chnTemp.ChainID
+	uniqueDependencies(chain.Dependencies)
+	return chain, nil
+}
+
+func unmarshalChainLevel(definition *viper.Viper) (*definitions.Chain, error) {
+	chain := definitions.BlankChain()
+	if err := definition.Unmarshal(chain); err != nil {
+		return nil, fmt.Errorf("The marmots coult not read the chain definition: %v", err)
+	}
+	resolveEnvFiles(chain.Service, definition)
 
 	// toml bools don't really marshal well "data_container". It can be
 	// in the chain or in the service layer.
@@ -145,8 +207,23 @@ func MarshalChainDefinition(definition *viper.Viper, chain *definitions.Chain) e
 			log.WithField("autodata", chain.Service.AutoData).Debug()
 		}
 	}
+	return chain, nil
+}
 
-	return nil
+// overwriteChain sets the chain.ChainID, chain.Cluster, chain.Service, and
+// chain.Dependencies fields of the chain (e.g. read from the "default"
+// definition) from the chnTemp definition. Ports and dependencies of
+// chnTemp replace the chain ones.
+func overwriteChain(chain, chnTemp *definitions.Chain) {
+	util.Merge(chain.Service, chnTemp.Service)
+	if len(chnTemp.Service.Ports) != 0 {
+		chain.Service.Ports = chnTemp.Service.Ports
+	}
+	chain.ChainID = chnTemp.ChainID
+	chain.Cluster = chnTemp.Cluster
+	if chnTemp.Dependencies != nil {
+		chain.Dependencies = chnTemp.Dependencies
+	}
 }
 
 func setChainDefaults(chain *definitions.Chain) error {
@@ -157,6 +234,7 @@ func setChainDefaults(chain *definitions.Chain) error {
 	if err := cfg.Unmarshal(chain); err != nil {
 		return err
 	}
+	resolveEnvFiles(chain.Service, cfg)
 
 	log.WithField("image", chain.Service.Image).Debug("Chain defaults set")
 	return nil
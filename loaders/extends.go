@@ -0,0 +1,95 @@
+package loaders
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+
+	"github.com/spf13/viper"
+)
+
+// InheritanceCycleError is returned by ExtendedDefinitions if definitions
+// extend each other. Path lists the definitions which form the cycle,
+// starting and ending with the same definition.
+type InheritanceCycleError struct {
+	Path []string
+}
+
+func (e *InheritanceCycleError) Error() string {
+	return fmt.Sprintf("Definition inheritance cycle detected: %s.\nPlease remove one of the extends keys from the definition files", strings.Join(e.Path, " -> "))
+}
+
+// ExtendedDefinitions returns the definition files the named definition
+// from the dir directory is made of: the definitions listed in its `extends`
+// key (a name or a list of names of definitions from the same directory),
+// their own bases recursively, and the definition itself, last. Every base
+// comes once and before the definitions which extend it, so the files
+// can be merged in order. ExtendedDefinitions returns
+// an *InheritanceCycleError if definitions extend each other.
+func ExtendedDefinitions(dir, name string, definition *viper.Viper) ([]*viper.Viper, error) {
+	var (
+		ordered []*viper.Viper
+		path    []string
+		visited = make(map[string]bool)
+	)
+
+	var visit func(name string, definition *viper.Viper) error
+	visit = func(name string, definition *viper.Viper) error {
+		for i, n := range path {
+			if n == name {
+				cycle := append([]string{}, path[i:]...)
+				return &InheritanceCycleError{Path: append(cycle, name)}
+			}
+		}
+		if visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+		for _, base := range definition.GetStringSlice("extends") {
+			baseDefinition, err := config.LoadViperConfig(dir, base)
+			if err != nil {
+				return fmt.Errorf("Cannot load the %s definition %s extends: %v", base, name, err)
+			}
+			if err := visit(base, baseDefinition); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+
+		visited[name] = true
+		ordered = append(ordered, definition)
+		return nil
+	}
+
+	if err := visit(name, definition); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// uniqueDependencies removes the dependencies listed
+// more than once by the definition and its bases.
+func uniqueDependencies(deps *definitions.Dependencies) {
+	if deps == nil {
+		return
+	}
+	deps.Services = unique(deps.Services)
+	deps.Chains = unique(deps.Chains)
+}
+
+func unique(list []string) []string {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	for _, item := range list {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
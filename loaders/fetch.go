@@ -0,0 +1,264 @@
+package loaders
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/definitions"
+
+	"github.com/eris-ltd/common/go/common"
+	"github.com/eris-ltd/common/go/ipfs"
+	log "github.com/eris-ltd/eris-logger"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// GithubRawURL is the address files from GitHub repositories
+	// are downloaded from.
+	GithubRawURL = "https://raw.githubusercontent.com"
+
+	// FetchTimeout limits the time spent downloading a definition file.
+	FetchTimeout = 30 * time.Second
+
+	// Maximum size of a definition file.
+	maxDefinitionSize int64 = 1 << 20
+)
+
+// Resolver returns the contents of the file at the location
+// and the file name the location refers to (if known).
+type Resolver func(location string) (contents []byte, fileName string, err error)
+
+// Resolvers maps definition file location schemes to their resolvers:
+//
+//  ipfs:HASH                    - file in IPFS (a bare HASH also works)
+//  github:ORG/REPO/PATH[@REF]   - file in a GitHub repository (REF is "master" by default)
+//  https://HOST/PATH            - file on a web server (http:// also works)
+//  file:///PATH                 - file on the host (a bare PATH also works)
+//
+var Resolvers = map[string]Resolver{
+	"ipfs":   resolveIPFS,
+	"github": resolveGithub,
+	"https":  resolveHTTP,
+	"http":   resolveHTTP,
+	"file":   resolveFile,
+}
+
+// LocationScheme returns the scheme of the definition file location
+// (a key of the Resolvers map). Locations without a known scheme are
+// local paths if the file exists or IPFS hashes otherwise.
+func LocationScheme(location string) string {
+	if i := strings.Index(location, ":"); i > 0 {
+		if scheme := strings.ToLower(location[:i]); Resolvers[scheme] != nil {
+			return scheme
+		}
+	}
+
+	if _, err := os.Stat(location); err == nil {
+		return "file"
+	}
+	return "ipfs"
+}
+
+// FetchDefinition downloads the definition file of the typ type ("chains",
+// "services", or "actions") from the location and validates it. It returns
+// the file contents and the file format extension (".toml" by default).
+func FetchDefinition(typ, location string) ([]byte, string, error) {
+	if definitionPath(typ) == "" {
+		return nil, "", fmt.Errorf("Don't know how to fetch %s definitions", typ)
+	}
+
+	contents, fileName, err := FetchFile(location)
+	if err != nil {
+		return nil, "", fmt.Errorf("Cannot fetch the definition file from %s: %v", location, err)
+	}
+
+	// Definitions are only looked up with the .yaml extension.
+	ext := strings.ToLower(filepath.Ext(fileName))
+	switch ext {
+	case ".toml", ".json", ".yaml":
+	case ".yml":
+		ext = ".yaml"
+	default:
+		ext = ".toml"
+	}
+
+	if err := ValidateDefinition(typ, contents, ext); err != nil {
+		return nil, "", fmt.Errorf("The file from %s is not a valid %s definition: %v", location, strings.TrimSuffix(typ, "s"), err)
+	}
+	return contents, ext, nil
+}
+
+// FetchFile returns the contents of the file at the location
+// (see Resolvers) and the file name the location refers to (if known).
+func FetchFile(location string) ([]byte, string, error) {
+	scheme := LocationScheme(location)
+	log.WithFields(log.Fields{
+		"location": location,
+		"scheme":   scheme,
+	}).Info("Fetching file")
+
+	return Resolvers[scheme](location)
+}
+
+// ImportDefinition fetches and validates the definition file of the
+// typ type from the location (see FetchDefinition) and writes it into
+// the corresponding Eris directory under the given name. It returns
+// the written file name.
+func ImportDefinition(typ, name, location string) (string, error) {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("Invalid %s definition name %q", strings.TrimSuffix(typ, "s"), name)
+	}
+
+	contents, ext, err := FetchDefinition(typ, location)
+	if err != nil {
+		return "", err
+	}
+
+	// Action names may contain spaces.
+	name = strings.Replace(strings.TrimSuffix(name, filepath.Ext(name)), " ", "_", -1)
+	if name == "" {
+		return "", fmt.Errorf("A name is required to import the %s definition", strings.TrimSuffix(typ, "s"))
+	}
+
+	fileName := filepath.Join(definitionPath(typ), name+ext)
+	if _, err := os.Stat(fileName); err == nil {
+		log.WithField("file", fileName).Warn("Overwriting definition file")
+	}
+
+	log.WithField("file", fileName).Warn("Writing definition file")
+	if err := ioutil.WriteFile(fileName, contents, 0644); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// ValidateDefinition checks that the contents of the definition file
+// of the typ type and ext format can be loaded and has the required fields.
+func ValidateDefinition(typ string, contents []byte, ext string) error {
+	if len(bytes.TrimSpace(contents)) == 0 {
+		return fmt.Errorf("the file is empty")
+	}
+
+	conf := viper.New()
+	conf.SetConfigType(strings.TrimPrefix(ext, "."))
+	if err := conf.ReadConfig(bytes.NewReader(contents)); err != nil {
+		return err
+	}
+
+	switch typ {
+	case "chains":
+		chain := definitions.BlankChain()
+		if err := MarshalChainDefinition(conf, chain); err != nil {
+			return err
+		}
+		if conf.GetString("name") == "" && chain.ChainID == "" {
+			return fmt.Errorf(`either a "name" or a "chain_id" field is required`)
+		}
+	case "services":
+		srv := definitions.BlankServiceDefinition()
+		if err := MarshalServiceDefinition(conf, srv); err != nil {
+			return err
+		}
+		return checkImage(srv.Service)
+	case "actions":
+		action := definitions.BlankAction()
+		if err := conf.Unmarshal(action); err != nil {
+			return err
+		}
+		if len(action.Steps) == 0 {
+			return fmt.Errorf(`a "steps" field is required`)
+		}
+	}
+	return nil
+}
+
+// definitionPath returns the directory definition
+// files of the typ type are imported into.
+func definitionPath(typ string) string {
+	switch typ {
+	case "chains":
+		return common.ChainsPath
+	case "services":
+		return common.ServicesPath
+	case "actions":
+		return common.ActionsPath
+	}
+	return ""
+}
+
+func resolveIPFS(location string) ([]byte, string, error) {
+	hash := strings.TrimLeft(strings.TrimPrefix(location, "ipfs:"), "/")
+	if hash == "" {
+		return nil, "", fmt.Errorf("no IPFS hash given")
+	}
+	contents, _, err := resolveHTTP(ipfs.IPFSBaseGatewayUrl("") + hash)
+	return contents, "", err
+}
+
+func resolveGithub(location string) ([]byte, string, error) {
+	file := strings.TrimLeft(strings.TrimPrefix(location, "github:"), "/")
+
+	ref := "master"
+	if i := strings.LastIndex(file, "@"); i >= 0 {
+		file, ref = file[:i], file[i+1:]
+	}
+
+	parts := strings.SplitN(file, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" || ref == "" {
+		return nil, "", fmt.Errorf("expecting github:ORG/REPO/PATH[@REF], got %q", location)
+	}
+
+	return resolveHTTP(strings.Join([]string{strings.TrimSuffix(GithubRawURL, "/"), parts[0], parts[1], ref, parts[2]}, "/"))
+}
+
+func resolveHTTP(location string) ([]byte, string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &http.Client{Timeout: FetchTimeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s returned %s", u, resp.Status)
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDefinitionSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(contents)) > maxDefinitionSize {
+		return nil, "", fmt.Errorf("the file is larger than %d bytes", maxDefinitionSize)
+	}
+	return contents, path.Base(u.Path), nil
+}
+
+func resolveFile(location string) ([]byte, string, error) {
+	fileName := strings.TrimPrefix(location, "file://")
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Size() > maxDefinitionSize {
+		return nil, "", fmt.Errorf("the file is larger than %d bytes", maxDefinitionSize)
+	}
+
+	contents, err := ioutil.ReadFile(fileName)
+	return contents, fileName, err
+}
@@ -0,0 +1,253 @@
+package loaders
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+// Built-in variables of definition files.
+const (
+	VarChain   = "chain"    // name of the chain (the --chain flag or the checked out chain)
+	VarChainID = "chain_id" // chain ID of that chain
+	VarErisDir = "eris_dir" // the Eris root directory (~/.eris)
+)
+
+// variable matches ${NAME}, ${NAME:-default}, and ${service:NAME.FIELD}
+// variables, as well as the escaped $${...} form.
+var variable = regexp.MustCompile(`\$?\$\{([^{}]*)\}`)
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Variables resolves the variables in the string fields of chain,
+// service, action, and package definitions:
+//
+//  ${VAR}                 - the VAR environment variable (required)
+//  ${VAR:-default}        - the VAR environment variable or default if VAR is unset or empty
+//  ${chain}               - name of the chain (see Variables.Chain)
+//  ${chain_id}            - chain ID of the chain (the chain name if the definition has none)
+//  ${eris_dir}            - the Eris root directory
+//  ${service:NAME.port}   - first container port of the NAME service, e.g. 4767 for keys
+//  ${service:NAME.ENDPT}  - container port of the ENDPT endpoint of the NAME service (see util.EndpointPort)
+//  ${service:NAME.image}  - image of the NAME service
+//  $${...}                - the literal ${...} text
+//
+// Fields tagged `interpolate:"shell"` hold shell commands (action steps,
+// container commands and health checks), so only the built-in variables
+// are resolved there and the rest is left for the shell.
+type Variables struct {
+	// Chain ${chain} and ${chain_id} refer to (the checked out chain if empty).
+	Chain string
+
+	undefined map[string]bool
+	errors    []string
+}
+
+// Interpolate resolves the variables in every string field (including
+// string slices and maps) of the definition structure v points to.
+// All undefined required variables are reported in one error.
+func Interpolate(v interface{}, chainName string) error {
+	vars := &Variables{Chain: chainName}
+	return vars.Interpolate(v)
+}
+
+// Interpolate resolves the variables in every string field of the
+// definition structure v points to (see Variables).
+func (vars *Variables) Interpolate(v interface{}) error {
+	vars.undefined = make(map[string]bool)
+	vars.errors = nil
+
+	vars.walk(reflect.ValueOf(v), false)
+
+	if len(vars.errors) != 0 {
+		return fmt.Errorf("%s", strings.Join(vars.errors, "; "))
+	}
+	if len(vars.undefined) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range vars.undefined {
+		names = append(names, "${"+name+"}")
+	}
+	sort.Strings(names)
+
+	hint := ""
+	if vars.undefined[VarChain] || vars.undefined[VarChainID] {
+		hint = ". The ${chain} variable needs either the --chain flag or a chain checked out with [eris chains checkout]"
+	}
+	return fmt.Errorf("Undefined variables %s. Please set them in the environment or give them defaults, e.g. ${VAR:-default}%s", strings.Join(names, ", "), hint)
+}
+
+// InterpolateString resolves the variables in a single string.
+func (vars *Variables) InterpolateString(s string) (string, error) {
+	if err := vars.Interpolate(&s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func (vars *Variables) walk(v reflect.Value, shell bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Operational fields are filled in at run time.
+		if _, ok := v.Interface().(*definitions.Operation); ok {
+			return
+		}
+		vars.walk(v.Elem(), shell)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			vars.walk(v.Field(i), t.Field(i).Tag.Get("interpolate") == "shell")
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			vars.walk(v.Index(i), shell)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.SetString(vars.resolve(v.MapIndex(key).String(), shell))
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(vars.resolve(v.String(), shell))
+		}
+	}
+}
+
+func (vars *Variables) resolve(s string, shell bool) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return variable.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		expr := match[2 : len(match)-1]
+		if strings.HasPrefix(expr, "service:") {
+			return vars.service(strings.TrimPrefix(expr, "service:"))
+		}
+
+		name, def, hasDefault := expr, "", false
+		if i := strings.Index(expr, ":-"); i >= 0 {
+			name, def, hasDefault = expr[:i], expr[i+2:], true
+		}
+		if !variableName.MatchString(name) {
+			if !shell {
+				vars.errors = append(vars.errors, fmt.Sprintf("Invalid variable %s", match))
+			}
+			return match
+		}
+
+		if value, ok := vars.builtin(name); ok {
+			if value != "" {
+				return value
+			}
+		} else if shell {
+			return match
+		} else if value := os.Getenv(name); value != "" {
+			return value
+		}
+
+		if !hasDefault {
+			vars.undefined[name] = true
+			return match
+		}
+		return def
+	})
+}
+
+// builtin returns the value of the built-in variable (empty if it
+// has no value) and false if the name isn't a built-in variable.
+func (vars *Variables) builtin(name string) (string, bool) {
+	switch name {
+	case VarChain:
+		return vars.chain(), true
+	case VarChainID:
+		chain := vars.chain()
+		if chain == "" {
+			return "", true
+		}
+		if definition, err := config.LoadViperConfig(common.ChainsPath, chain); err == nil && definition.GetString("chain_id") != "" {
+			return definition.GetString("chain_id"), true
+		}
+		return chain, true
+	case VarErisDir:
+		return common.ErisRoot, true
+	}
+	return "", false
+}
+
+func (vars *Variables) chain() string {
+	if vars.Chain == "" {
+		vars.Chain, _ = util.GetHead()
+	}
+	return vars.Chain
+}
+
+// service resolves the NAME.FIELD part of a ${service:NAME.FIELD}
+// variable. The service definition is read as is, without resolving
+// its own variables.
+func (vars *Variables) service(expr string) string {
+	i := strings.LastIndex(expr, ".")
+	if i <= 0 || i == len(expr)-1 {
+		vars.errors = append(vars.errors, fmt.Sprintf("Invalid variable ${service:%s}. Please use the ${service:NAME.FIELD} format, e.g. ${service:keys.port}", expr))
+		return ""
+	}
+	name, field := expr[:i], expr[i+1:]
+
+	definition, err := config.LoadViperConfig(common.ServicesPath, name)
+	if err != nil {
+		vars.errors = append(vars.errors, fmt.Sprintf("Cannot resolve ${service:%s}: unknown service %s", expr, name))
+		return ""
+	}
+	srv := definitions.BlankServiceDefinition()
+	if err := MarshalServiceDefinition(definition, srv); err != nil {
+		vars.errors = append(vars.errors, fmt.Sprintf("Cannot resolve ${service:%s}: %v", expr, err))
+		return ""
+	}
+	if srv.Service.Name == "" {
+		srv.Service.Name = name
+	}
+
+	switch field {
+	case "image":
+		return srv.Service.Image
+	case "port":
+		endpoints := util.Endpoints(srv.Service, nil)
+		if len(endpoints) == 0 {
+			vars.errors = append(vars.errors, fmt.Sprintf("Cannot resolve ${service:%s}: the %s service has no ports", expr, name))
+			return ""
+		}
+		return strings.Split(endpoints[0].Port, "/")[0]
+	}
+
+	port, err := util.EndpointPort(srv.Service, field)
+	if err != nil {
+		vars.errors = append(vars.errors, fmt.Sprintf("Cannot resolve ${service:%s}: %v", expr, err))
+		return ""
+	}
+	return port
+}
@@ -1,10 +1,12 @@
 package loaders
 
 import (
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/eris-ltd/eris-cli/config"
@@ -852,3 +854,498 @@ func TestServiceFinalizeLoadBlankAllTheThings(t *testing.T) {
 
 	t.Fatalf("expected finalize to panic")
 }
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("ERIS_TEST_IMAGE", "test image")
+	defer os.Unsetenv("ERIS_TEST_IMAGE")
+
+	if err := tests.FakeDefinitionFile(common.ChainsPath, "interpolated", `
+chain_id = "interpolated-id"
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	d := def.BlankServiceDefinition()
+	d.Chain = "${chain}"
+	d.Service.Image = "${ERIS_TEST_IMAGE}"
+	d.Service.Command = "run --id ${chain_id}"
+	d.Service.WorkDir = "/logs/${ERIS_TEST_LEVEL:-info}"
+	d.Service.Volumes = []string{"${eris_dir}/data:/data"}
+	d.Service.Endpoints = map[string]string{"api": "${ERIS_TEST_PORT:-1337}"}
+	d.Service.User = "$${ERIS_TEST_IMAGE}"
+
+	if err := Interpolate(d, "interpolated"); err != nil {
+		t.Fatalf("expected variables to resolve, got %v", err)
+	}
+
+	for _, entry := range []ab{
+		{`Chain`, d.Chain, "interpolated"},
+		{`Service.Image`, d.Service.Image, "test image"},
+		{`Service.Command`, d.Service.Command, "run --id interpolated-id"},
+		{`Service.WorkDir`, d.Service.WorkDir, "/logs/info"},
+		{`Service.Volumes`, d.Service.Volumes, []string{common.ErisRoot + "/data:/data"}},
+		{`Service.Endpoints`, d.Service.Endpoints, map[string]string{"api": "1337"}},
+		{`Service.User`, d.Service.User, "${ERIS_TEST_IMAGE}"},
+	} {
+		if !reflect.DeepEqual(entry.a, entry.b) {
+			t.Fatalf("definition expected %s = %#v, got %#v", entry.name, entry.b, entry.a)
+		}
+	}
+}
+
+func TestInterpolateUndefined(t *testing.T) {
+	d := def.BlankServiceDefinition()
+	d.Service.Image = "${ERIS_TEST_UNDEFINED_A}"
+	d.Service.Environment = []string{"B=${ERIS_TEST_UNDEFINED_B}", "A=${ERIS_TEST_UNDEFINED_A}"}
+
+	err := Interpolate(d, "")
+	if err == nil {
+		t.Fatalf("expected undefined variables to fail")
+	}
+	if !strings.Contains(err.Error(), "${ERIS_TEST_UNDEFINED_A}, ${ERIS_TEST_UNDEFINED_B}.") {
+		t.Fatalf("expected one error listing the undefined variables, got %v", err)
+	}
+}
+
+func TestInterpolateShell(t *testing.T) {
+	action := def.BlankAction()
+	action.Chain = "${chain}"
+	action.Steps = []string{"echo ${chain} ${prev} ${HOME:-none} $${chain}"}
+
+	if err := Interpolate(action, "test"); err != nil {
+		t.Fatalf("expected variables to resolve, got %v", err)
+	}
+	if action.Chain != "test" {
+		t.Fatalf("expected chain to resolve, got %q", action.Chain)
+	}
+	if action.Steps[0] != "echo test ${prev} ${HOME:-none} ${chain}" {
+		t.Fatalf("expected only built-in variables to resolve in steps, got %q", action.Steps[0])
+	}
+}
+
+func TestInterpolateContainerCommands(t *testing.T) {
+	d := def.BlankServiceDefinition()
+	d.Service.Command = "run --chain ${chain} --level ${LEVEL:-info}"
+	d.Service.EntryPoint = "sh -c 'exec ${BINARY}'"
+	d.Service.HealthCheck = &def.HealthCheck{
+		Exec: "curl -f http://localhost:${PORT}/status?chain=${chain}",
+	}
+
+	if err := Interpolate(d, "test"); err != nil {
+		t.Fatalf("expected container side variables to be left unresolved, got %v", err)
+	}
+
+	for _, entry := range []ab{
+		{`Service.Command`, d.Service.Command, "run --chain test --level ${LEVEL:-info}"},
+		{`Service.EntryPoint`, d.Service.EntryPoint, "sh -c 'exec ${BINARY}'"},
+		{`Service.HealthCheck.Exec`, d.Service.HealthCheck.Exec, "curl -f http://localhost:${PORT}/status?chain=test"},
+	} {
+		if !reflect.DeepEqual(entry.a, entry.b) {
+			t.Fatalf("definition expected %s = %#v, got %#v", entry.name, entry.b, entry.a)
+		}
+	}
+}
+
+func TestInterpolateService(t *testing.T) {
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "interpolated", `
+[service]
+image = "keys image"
+ports = [ "4767" ]
+
+[service.endpoints]
+signer = "4767"
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	vars := &Variables{}
+	for expr, expected := range map[string]string{
+		"${service:interpolated.port}":   "4767",
+		"${service:interpolated.signer}": "4767",
+		"${service:interpolated.image}":  "keys image",
+	} {
+		value, err := vars.InterpolateString(expr)
+		if err != nil {
+			t.Fatalf("expected %s to resolve, got %v", expr, err)
+		}
+		if value != expected {
+			t.Fatalf("expected %s = %q, got %q", expr, expected, value)
+		}
+	}
+
+	for _, expr := range []string{"${service:interpolated.rpc}", "${service:missing.port}", "${service:interpolated}"} {
+		if _, err := vars.InterpolateString(expr); err == nil {
+			t.Fatalf("expected %s to fail", expr)
+		}
+	}
+}
+
+func TestExtendedDefinitions(t *testing.T) {
+	for name, definition := range map[string]string{
+		"extends_base":    `image = "base"`,
+		"extends_middle":  `extends = "extends_base"`,
+		"extends_other":   `extends = "extends_base"`,
+		"extends_derived": `extends = [ "extends_middle", "extends_other" ]`,
+	} {
+		if err := tests.FakeDefinitionFile(common.ServicesPath, name, definition); err != nil {
+			t.Fatalf("cannot place a definition file")
+		}
+	}
+
+	definition, err := config.LoadViperConfig(common.ServicesPath, "extends_derived")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+	levels, err := ExtendedDefinitions(common.ServicesPath, "extends_derived", definition)
+	if err != nil {
+		t.Fatalf("expected definitions to resolve, got %v", err)
+	}
+
+	var names []string
+	for _, level := range levels {
+		names = append(names, strings.TrimSuffix(filepath.Base(level.ConfigFileUsed()), ".toml"))
+	}
+	if expected := []string{"extends_base", "extends_middle", "extends_other", "extends_derived"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected definitions %v, got %v", expected, names)
+	}
+}
+
+func TestExtendedDefinitionsCycle(t *testing.T) {
+	for name, definition := range map[string]string{
+		"cycle_a": `extends = "cycle_b"`,
+		"cycle_b": `extends = "cycle_c"`,
+		"cycle_c": `extends = "cycle_b"`,
+	} {
+		if err := tests.FakeDefinitionFile(common.ServicesPath, name, definition); err != nil {
+			t.Fatalf("cannot place a definition file")
+		}
+	}
+
+	definition, err := config.LoadViperConfig(common.ServicesPath, "cycle_a")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+	_, err = ExtendedDefinitions(common.ServicesPath, "cycle_a", definition)
+	cycle, ok := err.(*InheritanceCycleError)
+	if !ok {
+		t.Fatalf("expected inheritance cycle error, got %v", err)
+	}
+	if expected := []string{"cycle_b", "cycle_c", "cycle_b"}; !reflect.DeepEqual(cycle.Path, expected) {
+		t.Fatalf("expected cycle %v, got %v", expected, cycle.Path)
+	}
+}
+
+func TestResolveServiceDefinition(t *testing.T) {
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "resolve_base", `
+name = "resolve_base"
+
+[service]
+name = "resolve_base"
+image = "base image"
+ports = [ "1234" ]
+environment = [ "A=1", "B=1" ]
+
+[dependencies]
+services = [ "keys" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "resolve_derived", `
+extends = "resolve_base"
+
+[service]
+ports = [ "5678" ]
+environment = [ "B=2" ]
+
+[dependencies]
+services = [ "keys", "ipfs" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	d, err := ResolveServiceDefinition("resolve_derived", "")
+	if err != nil {
+		t.Fatalf("expected definition to resolve, got %v", err)
+	}
+
+	for _, entry := range []ab{
+		{`Name`, d.Name, ""},
+		{`Service.Name`, d.Service.Name, ""},
+		{`Service.Image`, d.Service.Image, "base image"},
+		{`Service.Ports`, d.Service.Ports, []string{"1234", "5678"}},
+		{`Service.Environment`, d.Service.Environment, []string{"A=1", "B=1", "B=2"}},
+		{`Dependencies.Services`, d.Dependencies.Services, []string{"keys", "ipfs"}},
+	} {
+		if !reflect.DeepEqual(entry.a, entry.b) {
+			t.Fatalf("definition expected %s = %#v, got %#v", entry.name, entry.b, entry.a)
+		}
+	}
+}
+
+func TestMergeChainLevels(t *testing.T) {
+	if err := tests.FakeDefinitionFile(common.ChainsPath, "merge_base", `
+name = "merge_base"
+chain_id = "base-id"
+cluster = "base-cluster"
+
+[service]
+image = "base image"
+ports = [ "46657" ]
+
+[dependencies]
+services = [ "keys" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if err := tests.FakeDefinitionFile(common.ChainsPath, "merge_derived", `
+name = "merge_derived"
+extends = "merge_base"
+
+[service]
+ports = [ "46656" ]
+
+[dependencies]
+services = [ "keys", "ipfs" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	definition, err := config.LoadViperConfig(common.ChainsPath, "merge_derived")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+	levels, err := DefinitionLayers(common.ChainsPath, "merge_derived", definition)
+	if err != nil {
+		t.Fatalf("expected definition layers, got %v", err)
+	}
+
+	d, err := mergeChainLevels(definition, levels)
+	if err != nil {
+		t.Fatalf("expected definition to merge, got %v", err)
+	}
+
+	for _, entry := range []ab{
+		{`Name`, d.Name, "merge_derived"},
+		{`ChainID`, d.ChainID, "base-id"},
+		{`Cluster`, d.Cluster, "base-cluster"},
+		{`Service.Image`, d.Service.Image, "base image"},
+		{`Service.Ports`, d.Service.Ports, []string{"46657", "46656"}},
+		{`Dependencies.Services`, d.Dependencies.Services, []string{"keys", "ipfs"}},
+	} {
+		if !reflect.DeepEqual(entry.a, entry.b) {
+			t.Fatalf("definition expected %s = %#v, got %#v", entry.name, entry.b, entry.a)
+		}
+	}
+}
+
+func TestResolveServiceDefinitionOverlay(t *testing.T) {
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "overlaid", `
+name = "overlaid"
+
+[service]
+image = "local image"
+ports = [ "1234" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "overlaid.staging", `
+[service]
+image = "staging image"
+ports = [ "5678" ]
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+
+	defer func() { Environment = "" }()
+	for _, entry := range []struct {
+		environment string
+		image       string
+		ports       []string
+	}{
+		{"", "local image", []string{"1234"}},
+		{"demo", "local image", []string{"1234"}},
+		{"staging", "staging image", []string{"5678"}},
+	} {
+		Environment = entry.environment
+		d, err := ResolveServiceDefinition("overlaid", "")
+		if err != nil {
+			t.Fatalf("expected definition to resolve in %q environment, got %v", entry.environment, err)
+		}
+		if d.Name != "overlaid" || d.Service.Image != entry.image || !reflect.DeepEqual(d.Service.Ports, entry.ports) {
+			t.Fatalf("expected %q environment definition %q, %q, %v, got %q, %q, %v", entry.environment, "overlaid", entry.image, entry.ports, d.Name, d.Service.Image, d.Service.Ports)
+		}
+	}
+}
+
+func TestLoadPackageOverlay(t *testing.T) {
+	if err := tests.FakeDefinitionFile(common.ErisRoot, "package", `
+[eris]
+name       = "test"
+chain_name = "local chain"
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	if err := tests.FakeDefinitionFile(common.ErisRoot, "package.demo", `
+[eris]
+chain_name = "demo chain"
+`); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	defer os.Remove(filepath.Join(common.ErisRoot, "package.demo.toml"))
+
+	Environment = "demo"
+	defer func() { Environment = "" }()
+
+	d, err := LoadPackage(common.ErisRoot, "test")
+	if err != nil {
+		t.Fatalf("expected to load definition file, got %v", err)
+	}
+	if d.Name != "test" || d.ChainName != "demo chain" {
+		t.Fatalf("expected overlaid package %q on %q, got %q on %q", "test", "demo chain", d.Name, d.ChainName)
+	}
+}
+
+func TestLocationScheme(t *testing.T) {
+	file := filepath.Join(common.ServicesPath, "scheme.toml")
+	if err := tests.FakeDefinitionFile(common.ServicesPath, "scheme", ``); err != nil {
+		t.Fatalf("cannot place a definition file")
+	}
+	defer os.Remove(file)
+
+	for _, entry := range []ab{
+		{`ipfs`, LocationScheme("ipfs:QmQ1LZYPNG4wSb9dojRicWCmM4gFLTPKFUhFnMTR3GKuA2"), "ipfs"},
+		{`hash`, LocationScheme("QmQ1LZYPNG4wSb9dojRicWCmM4gFLTPKFUhFnMTR3GKuA2"), "ipfs"},
+		{`github`, LocationScheme("github:eris-ltd/eris-services/eth.toml@master"), "github"},
+		{`https`, LocationScheme("https://example.com/eth.toml"), "https"},
+		{`file`, LocationScheme("file://" + file), "file"},
+		{`path`, LocationScheme(file), "file"},
+	} {
+		if entry.a != entry.b {
+			t.Fatalf("location scheme expected %s = %#v, got %#v", entry.name, entry.b, entry.a)
+		}
+	}
+}
+
+func TestImportDefinitionGithub(t *testing.T) {
+	const (
+		name = "fetched"
+
+		definition = `
+name = "` + name + `"
+
+[service]
+image = "test image"
+`
+	)
+
+	server := tests.NewServer()
+	server.SetResponse(tests.ServerResponse{
+		Code: http.StatusOK,
+		Body: definition,
+	})
+	defer server.Close()
+
+	defer func(url string) { GithubRawURL = url }(GithubRawURL)
+	GithubRawURL = server.URL()
+
+	fileName, err := ImportDefinition("services", name, "github:eris-ltd/eris-services/dir/"+name+".toml@v1")
+	if err != nil {
+		t.Fatalf("expected definition to be imported, got %v", err)
+	}
+	defer os.Remove(fileName)
+
+	if expected := "/eris-ltd/eris-services/v1/dir/" + name + ".toml"; server.Path() != expected {
+		t.Fatalf("called the wrong endpoint; expected %v, got %v", expected, server.Path())
+	}
+	if expected := filepath.Join(common.ServicesPath, name+".toml"); fileName != expected {
+		t.Fatalf("expected definition to be written to %v, got %v", expected, fileName)
+	}
+	if imported := tests.FileContents(fileName); imported != definition {
+		t.Fatalf("returned unexpected content; expected: %v, got %v", definition, imported)
+	}
+}
+
+func TestImportDefinitionHTTPFormat(t *testing.T) {
+	const (
+		name = "fetched"
+
+		definition = `{"name": "` + name + `", "steps": ["echo marmot"]}`
+	)
+
+	server := tests.NewServer()
+	server.SetResponse(tests.ServerResponse{
+		Code: http.StatusOK,
+		Body: definition,
+	})
+	defer server.Close()
+
+	fileName, err := ImportDefinition("actions", "fetched action", server.URL()+"/actions/"+name+".json")
+	if err != nil {
+		t.Fatalf("expected definition to be imported, got %v", err)
+	}
+	defer os.Remove(fileName)
+
+	if expected := filepath.Join(common.ActionsPath, "fetched_action.json"); fileName != expected {
+		t.Fatalf("expected definition to be written to %v, got %v", expected, fileName)
+	}
+}
+
+func TestImportDefinitionYML(t *testing.T) {
+	const name = "fetched"
+
+	server := tests.NewServer()
+	server.SetResponse(tests.ServerResponse{
+		Code: http.StatusOK,
+		Body: "name: " + name + "\nservice:\n  image: test image\n",
+	})
+	defer server.Close()
+
+	fileName, err := ImportDefinition("services", name, server.URL()+"/"+name+".yml")
+	if err != nil {
+		t.Fatalf("expected definition to be imported, got %v", err)
+	}
+	defer os.Remove(fileName)
+
+	if expected := filepath.Join(common.ServicesPath, name+".yaml"); fileName != expected {
+		t.Fatalf("expected definition to be written to %v, got %v", expected, fileName)
+	}
+}
+
+func TestImportDefinitionBadName(t *testing.T) {
+	server := tests.NewServer()
+	server.SetResponse(tests.ServerResponse{
+		Code: http.StatusOK,
+		Body: "name = \"fetched\"\n[service]\nimage = \"test image\"\n",
+	})
+	defer server.Close()
+
+	for _, name := range []string{"../fetched", "dir/fetched", "fetched/..", ".."} {
+		if fileName, err := ImportDefinition("services", name, server.URL()+"/fetched.toml"); err == nil {
+			os.Remove(fileName)
+			t.Fatalf("expected the %q name to be rejected", name)
+		}
+	}
+}
+
+func TestImportDefinitionInvalid(t *testing.T) {
+	const name = "invalid"
+
+	server := tests.NewServer()
+	defer server.Close()
+
+	for _, response := range []tests.ServerResponse{
+		{Code: http.StatusNotFound, Body: "not found"},
+		{Code: http.StatusOK, Body: ""},
+		{Code: http.StatusOK, Body: "<html><body>not a definition</body></html>"},
+		{Code: http.StatusOK, Body: "name = \"" + name + "\"\n[service]\nports = [\"1234\"]\n"},
+	} {
+		server.SetResponse(response)
+
+		if _, err := ImportDefinition("services", name, server.URL()+"/"+name+".toml"); err == nil {
+			t.Fatalf("expected %q (%d) to be rejected", response.Body, response.Code)
+		}
+		if _, err := os.Stat(filepath.Join(common.ServicesPath, name+".toml")); !os.IsNotExist(err) {
+			t.Fatalf("expected rejected definition not to be written")
+		}
+	}
+}
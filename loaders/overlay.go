@@ -0,0 +1,64 @@
+package loaders
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/eris-ltd/eris-cli/config"
+
+	log "github.com/eris-ltd/eris-logger"
+
+	"github.com/spf13/viper"
+)
+
+// Environment is the name of the environment (e.g. "staging") whose overlay
+// files are merged over the definitions (see OverlayDefinition). It defaults
+// to the ERIS_ENV environment variable and is set by the global
+// --environment flag.
+var Environment = os.Getenv("ERIS_ENV")
+
+// OverlayDefinition reads the overlay of the named definition from the dir
+// directory (or its config.DefinitionPaths) for the current Environment,
+// that is the NAME.ENV.toml (or .json, .yaml) file. OverlayDefinition returns nil if no environment is
+// set or the definition has no overlay for it.
+func OverlayDefinition(dir, name string) (*viper.Viper, error) {
+	if Environment == "" {
+		return nil, nil
+	}
+
+	overlay := name + "." + Environment
+	for _, path := range config.DefinitionPaths(dir) {
+		for _, ext := range []string{"json", "yaml", "toml"} {
+			if _, err := os.Stat(filepath.Join(path, overlay+"."+ext)); err != nil {
+				continue
+			}
+
+			log.WithFields(log.Fields{
+				"=>":          name,
+				"environment": Environment,
+			}).Debug("Loading definition overlay")
+			return config.LoadViperConfig(dir, overlay)
+		}
+	}
+	return nil, nil
+}
+
+// DefinitionLayers returns the definition files the named definition from
+// the dir directory is merged from, in order: the definitions it extends and
+// the definition itself (see ExtendedDefinitions), then its overlay for the
+// current Environment, if any (see OverlayDefinition).
+func DefinitionLayers(dir, name string, definition *viper.Viper) ([]*viper.Viper, error) {
+	layers, err := ExtendedDefinitions(dir, name, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay, err := OverlayDefinition(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if overlay != nil {
+		layers = append(layers, overlay)
+	}
+	return layers, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
 
 	log "github.com/eris-ltd/eris-logger"
 
@@ -16,6 +17,8 @@ import (
 
 // LoadPackage loads a package definition specified by the directory or
 // filename path and chainName and returns a package definition structure.
+// The package.ENV.json overlay for the current Environment next to the
+// package.json file is merged over it (see OverlayDefinition).
 // LoadPackage can also return missing files or package loading errors.
 func LoadPackage(path, chainName string) (*definitions.Package, error) {
 	var name string
@@ -36,11 +39,10 @@ func LoadPackage(path, chainName string) (*definitions.Package, error) {
 
 	var pkgConf *viper.Viper
 	var pkg *definitions.Package
-	if dir {
-		pkgConf, err = loadPackage(path)
-	} else {
-		pkgConf, err = loadPackage(filepath.Dir(path))
+	if !dir {
+		path = filepath.Dir(path)
 	}
+	pkgConf, err = loadPackage(path)
 
 	if err != nil {
 		log.Info("The marmots could not read that package.json. Will use defaults.")
@@ -55,6 +57,24 @@ func LoadPackage(path, chainName string) (*definitions.Package, error) {
 		}
 	}
 
+	overlay, err := OverlayDefinition(path, "package")
+	if err != nil {
+		return nil, err
+	}
+	if overlay != nil {
+		overlayPkg, err := marshalPackage(overlay)
+		if err != nil {
+			return nil, err
+		}
+		if err := util.DeepOverlay(pkg, overlayPkg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := Interpolate(pkg, chainName); err != nil {
+		return nil, fmt.Errorf("Cannot load the %s package definition: %v", name, err)
+	}
+
 	checkName(pkg, chainName)
 
 	return pkg, nil
@@ -22,27 +22,75 @@ import (
 // LoadServiceDefinition can return missing file or definition file bad format
 // errors.
 func LoadServiceDefinition(servName string) (*definitions.ServiceDefinition, error) {
+	return LoadServiceDefinitionOnChain(servName, "")
+}
+
+// LoadServiceDefinitionOnChain is LoadServiceDefinition with the ${chain}
+// and ${chain_id} variables of the definition file referring to chainName
+// (the checked out chain if empty). See Variables.
+func LoadServiceDefinitionOnChain(servName, chainName string) (*definitions.ServiceDefinition, error) {
 	log.WithField("=>", servName).Debug("Loading service definition")
 
-	srv := definitions.BlankServiceDefinition()
+	srv, err := ResolveServiceDefinition(servName, chainName)
+	if err != nil {
+		return nil, err
+	}
 	srv.Operations.ContainerType = definitions.TypeService
 	srv.Operations.Labels = util.Labels(servName, srv.Operations)
-	serviceConf, err := loadServiceDefinition(servName)
-	if err != nil {
+
+	if err = checkImage(srv.Service); err != nil {
 		return nil, err
 	}
 
-	if err = MarshalServiceDefinition(serviceConf, srv); err != nil {
+	addDependencyVolumesAndLinks(srv.Dependencies, srv.Service, srv.Operations)
+
+	ServiceFinalizeLoad(srv)
+	return srv, nil
+}
+
+// ResolveServiceDefinition reads a service definition specified by a service
+// name from the common.ServicesPath directory, merges it over the definitions
+// it extends and its environment overlay over it (see DefinitionLayers), and
+// resolves its variables with ${chain} referring to chainName (see
+// Variables). The names of the base definitions and of the overlay are
+// not inherited. Unlike LoadServiceDefinitionOnChain,
+// ResolveServiceDefinition doesn't fill in the operational fields.
+func ResolveServiceDefinition(servName, chainName string) (*definitions.ServiceDefinition, error) {
+	serviceConf, err := loadServiceDefinition(servName)
+	if err != nil {
 		return nil, err
 	}
 
-	if err = checkImage(srv.Service); err != nil {
+	levels, err := DefinitionLayers(common.ServicesPath, servName, serviceConf)
+	if err != nil {
 		return nil, err
 	}
 
-	addDependencyVolumesAndLinks(srv.Dependencies, srv.Service, srv.Operations)
+	// The lists of the overlay (the layer after the
+	// definition itself) replace the definition ones.
+	merge := util.DeepMerge
+	srv := definitions.BlankServiceDefinition()
+	for _, level := range levels {
+		levelSrv := definitions.BlankServiceDefinition()
+		if err = MarshalServiceDefinition(level, levelSrv); err != nil {
+			return nil, err
+		}
+		if level != serviceConf {
+			levelSrv.Name = ""
+			levelSrv.Service.Name = ""
+		}
+		if err = merge(srv, levelSrv); err != nil {
+			return nil, err
+		}
+		if level == serviceConf {
+			merge = util.DeepOverlay
+		}
+	}
+	uniqueDependencies(srv.Dependencies)
 
-	ServiceFinalizeLoad(srv)
+	if err = Interpolate(srv, chainName); err != nil {
+		return nil, fmt.Errorf("Cannot load the %s service definition: %v", servName, err)
+	}
 	return srv, nil
 }
 
@@ -75,6 +123,8 @@ func MarshalServiceDefinition(serviceConf *viper.Viper, srv *definitions.Service
 		srv.Service.AutoData = true
 	}
 
+	resolveEnvFiles(srv.Service, serviceConf)
+
 	return nil
 }
 
@@ -137,6 +187,18 @@ func loadServiceDefinition(servName string) (*viper.Viper, error) {
 	return config.LoadViperConfig(filepath.Join(common.ServicesPath), servName)
 }
 
+// resolveEnvFiles makes the env_file paths relative to the directory
+// of the definition file absolute.
+func resolveEnvFiles(srv *definitions.Service, definition *viper.Viper) {
+	dir := filepath.Dir(definition.ConfigFileUsed())
+
+	for i, file := range srv.EnvFile {
+		if !filepath.IsAbs(file) {
+			srv.EnvFile[i] = filepath.Join(dir, file)
+		}
+	}
+}
+
 func checkImage(srv *definitions.Service) error {
 	// Services must be given an image. Flame out if they do not.
 	if srv.Image == "" {
@@ -11,12 +11,14 @@ import (
 	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
-	"unicode"
+	"time"
 
 	"github.com/eris-ltd/eris-cli/config"
 	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/rpc"
 	"github.com/eris-ltd/eris-cli/util"
 
 	dirs "github.com/eris-ltd/common/go/common"
@@ -29,6 +31,8 @@ import (
 
 var (
 	ErrContainerExists = errors.New("container exists")
+
+	networkName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
 )
 
 // DockerCreateData creates a blank data container. It returns ErrContainerExists
@@ -189,6 +193,10 @@ func DockerExecData(ops *def.Operation, service *def.Service) (buf *bytes.Buffer
 // Container parameters:
 //
 //  ops.PublishAllPorts   - if true, publish exposed ports to random ports
+//  ops.Ports             - reassign published ports (see util.MapPorts)
+//  ops.AutoPorts         - if true, publish to free host ports the ports
+//                          which are taken by other containers or processes
+//                          (otherwise fail, see util.CheckPorts)
 //  ops.CapAdd            - add linux capabilities (similar to `docker run --cap-add=[]`)
 //  ops.CapDrop           - add linux capabilities (similar to `docker run --cap-drop=[]`)
 //  ops.Privileged        - if true, give extended privileges
@@ -202,7 +210,21 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 		return nil
 	}
 
-	optsServ := configureServiceContainer(srv, ops)
+	// Ports of existing containers are bound already.
+	if !ContainerExists(ops.SrvContainerName) && !ops.PublishAllPorts && len(srv.Ports) > 0 {
+		assignments, err := util.CheckPorts(srv.Ports, util.SplitPorts(ops.Ports), ops.SrvContainerName, ops.AutoPorts)
+		if err != nil {
+			return err
+		}
+		if ops.AutoPorts {
+			ops.Ports = strings.Join(assignments, ",")
+		}
+	}
+
+	optsServ, err := configureServiceContainer(srv, ops)
+	if err != nil {
+		return err
+	}
 
 	// Setup data container.
 	log.WithField("autodata", srv.AutoData).Info("Manage data containers?")
@@ -254,6 +276,124 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 	return nil
 }
 
+// DockerWaitHealthy waits until the running chain or service container
+// passes the srv.HealthCheck check (see definitions.HealthCheck). The check
+// is repeated every interval until it succeeds or the number of retries
+// is exhausted. If srv.HealthCheck is nil, DockerWaitHealthy returns
+// immediately.
+//
+//  ops.SrvContainerName  - container name
+//
+func DockerWaitHealthy(srv *def.Service, ops *def.Operation) error {
+	if srv.HealthCheck == nil {
+		return nil
+	}
+
+	interval, _, retries, err := util.HealthCheckParams(srv.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("=>", ops.SrvContainerName).Info("Waiting for container to become healthy")
+	for i := 1; ; i++ {
+		container, err := util.DockerClient.InspectContainer(ops.SrvContainerName)
+		if err != nil {
+			return err
+		}
+
+		err = util.CheckHealth(container, srv.HealthCheck)
+		if err == nil {
+			log.WithField("=>", ops.SrvContainerName).Info("Container is healthy")
+			return nil
+		}
+		if !container.State.Running || i >= retries {
+			return fmt.Errorf("Container %s is unhealthy: %v", ops.SrvContainerName, err)
+		}
+
+		log.WithFields(log.Fields{
+			"=>":    ops.SrvContainerName,
+			"check": i,
+		}).Debugf("Health check failed: %v", err)
+		time.Sleep(interval)
+	}
+}
+
+// DockerWaitChainReady waits until the running chain container answers
+// the RPC status requests and its block height moves, polling the chain
+// every second. If the chain isn't ready after timeout or the container
+// stops, DockerWaitChainReady returns an error with the last container
+// log lines.
+//
+//  ops.SrvContainerName  - container name
+//
+func DockerWaitChainReady(srv *def.Service, ops *def.Operation, timeout time.Duration) error {
+	log.WithFields(log.Fields{
+		"=>":      ops.SrvContainerName,
+		"timeout": timeout,
+	}).Info("Waiting for chain to produce blocks")
+
+	var (
+		first = -1
+		err   error
+	)
+	deadline := time.Now().Add(timeout)
+	for {
+		var container *docker.Container
+		container, err = util.DockerClient.InspectContainer(ops.SrvContainerName)
+		if err != nil {
+			return util.DockerError(err)
+		}
+		if !container.State.Running {
+			return fmt.Errorf("Chain container %s has stopped. The last log lines:\n%s", ops.SrvContainerName, lastLogs(ops.SrvContainerName))
+		}
+
+		var address string
+		if address, err = util.EndpointAddress(container, srv, def.EndpointRPC); err == nil {
+			client := rpc.NewClient(address)
+			client.Timeout = time.Second
+
+			var status *rpc.Status
+			if status, err = client.Status(); err == nil {
+				log.WithFields(log.Fields{
+					"=>":     ops.SrvContainerName,
+					"height": status.LatestBlockHeight,
+				}).Debug("Chain status")
+				switch {
+				case first < 0:
+					first = status.LatestBlockHeight
+				case status.LatestBlockHeight > first:
+					log.WithField("=>", ops.SrvContainerName).Info("Chain is ready")
+					return nil
+				}
+				err = fmt.Errorf("block height is stuck at %d", status.LatestBlockHeight)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Chain %s is not ready after %v: %v. The last log lines:\n%s", ops.SrvContainerName, timeout, err, lastLogs(ops.SrvContainerName))
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// lastLogs returns the last lines of the container output.
+func lastLogs(name string) string {
+	buf := new(bytes.Buffer)
+	opts := docker.LogsOptions{
+		Container:    name,
+		OutputStream: buf,
+		ErrorStream:  buf,
+		Stdout:       true,
+		Stderr:       true,
+		Tail:         "20",
+		RawTerminal:  true,
+	}
+	if err := util.DockerClient.Logs(opts); err != nil {
+		return fmt.Sprintf("(cannot get the logs: %v)", err)
+	}
+	return buf.String()
+}
+
 // DockerExecService creates and runs a chain or a service container interactively.
 //
 //  ops.Args         - command line parameters
@@ -264,7 +404,10 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 func DockerExecService(srv *def.Service, ops *def.Operation) (buf *bytes.Buffer, err error) {
 	log.WithField("=>", ops.SrvContainerName).Info("Executing container")
 
-	optsServ := configureInteractiveContainer(srv, ops)
+	optsServ, err := configureInteractiveContainer(srv, ops)
+	if err != nil {
+		return nil, err
+	}
 
 	// Setup data container.
 	log.WithField("autodata", srv.AutoData).Info("Manage data containers?")
@@ -375,10 +518,13 @@ func DockerRebuild(srv *def.Service, ops *def.Operation, pullImage bool, timeout
 		}
 	}
 
-	opts := configureServiceContainer(srv, ops)
+	opts, err := configureServiceContainer(srv, ops)
+	if err != nil {
+		return err
+	}
 
 	log.WithField("=>", ops.SrvContainerName).Info("Recreating container")
-	_, err := createContainer(opts)
+	_, err = createContainer(opts)
 	if err != nil {
 		return err
 	}
@@ -933,8 +1079,11 @@ func removeContainer(id string, volumes, force bool) error {
 	return nil
 }
 
-func configureInteractiveContainer(srv *def.Service, ops *def.Operation) docker.CreateContainerOptions {
-	opts := configureServiceContainer(srv, ops)
+func configureInteractiveContainer(srv *def.Service, ops *def.Operation) (docker.CreateContainerOptions, error) {
+	opts, err := configureServiceContainer(srv, ops)
+	if err != nil {
+		return docker.CreateContainerOptions{}, err
+	}
 
 	opts.Name = util.UniqueName("interactive")
 	if srv.User == "" {
@@ -979,10 +1128,42 @@ func configureInteractiveContainer(srv *def.Service, ops *def.Operation) docker.
 	// Ignore the restart policy of a container.
 	opts.HostConfig.RestartPolicy = docker.NeverRestart()
 
-	return opts
+	return opts, nil
 }
 
-func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.CreateContainerOptions {
+func configureServiceContainer(srv *def.Service, ops *def.Operation) (docker.CreateContainerOptions, error) {
+	// Variables from env files come first, so that the ones
+	// in the environment field take precedence (as in `docker run`).
+	env, err := util.ParseEnvFiles(srv.EnvFile)
+	if err != nil {
+		return docker.CreateContainerOptions{}, err
+	}
+	env = append(env, srv.Environment...)
+
+	if err := checkPIDMode(srv.PID); err != nil {
+		return docker.CreateContainerOptions{}, err
+	}
+	if err := checkNetworkMode(srv.Net, srv.Links); err != nil {
+		return docker.CreateContainerOptions{}, err
+	}
+
+	// Keep the health check with the container, so that
+	// it can be looked up without the definition file.
+	labels := make(map[string]string)
+	for k, v := range ops.Labels {
+		labels[k] = v
+	}
+	if srv.HealthCheck != nil {
+		if _, _, _, err := util.HealthCheckParams(srv.HealthCheck); err != nil {
+			return docker.CreateContainerOptions{}, err
+		}
+		labels[def.LabelHealthCheck] = util.HealthCheckLabel(srv.HealthCheck)
+	}
+	// The same goes for the port reassignments.
+	if ops.Ports != "" && !ops.PublishAllPorts {
+		labels[def.LabelPorts] = ops.Ports
+	}
+
 	opts := docker.CreateContainerOptions{
 		Name: ops.SrvContainerName,
 		Config: &docker.Config{
@@ -996,8 +1177,8 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 			AttachStderr:    false,
 			Tty:             false,
 			OpenStdin:       false,
-			Env:             srv.Environment,
-			Labels:          ops.Labels,
+			Env:             env,
+			Labels:          labels,
 			Image:           srv.Image,
 			NetworkDisabled: false,
 		},
@@ -1012,6 +1193,8 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 			VolumesFrom:     srv.VolumesFrom,
 			CapAdd:          ops.CapAdd,
 			CapDrop:         ops.CapDrop,
+			NetworkMode:     srv.Net,
+			PidMode:         srv.PID,
 			RestartPolicy:   docker.NeverRestart(), //default. overide below
 		},
 	}
@@ -1033,7 +1216,7 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 	} else if strings.Contains(srv.Restart, "max") {
 		times, err := strconv.Atoi(strings.Split(srv.Restart, ":")[1])
 		if err != nil {
-			return docker.CreateContainerOptions{}
+			return docker.CreateContainerOptions{}, fmt.Errorf("Invalid restart policy %q: expecting \"always\" or \"max:<#attempts>\"", srv.Restart)
 		}
 		opts.HostConfig.RestartPolicy = docker.RestartOnFailure(times)
 	}
@@ -1044,9 +1227,7 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 
 	// Don't fill in port bindings if randomizing the ports.
 	if !ops.PublishAllPorts {
-		ports := util.MapPorts(srv.Ports, strings.FieldsFunc(ops.Ports, func(c rune) bool {
-			return unicode.IsSpace(c) || c == ','
-		}))
+		ports := util.MapPorts(srv.Ports, util.SplitPorts(ops.Ports))
 
 		for _, entry := range srv.Ports {
 			ip, _, exposed := util.PortComponents(entry)
@@ -1061,6 +1242,16 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 		}
 	}
 
+	// Exposed ports are not published, but are still available
+	// to linked containers.
+	for _, entry := range srv.Expose {
+		port, err := exposedPort(entry)
+		if err != nil {
+			return docker.CreateContainerOptions{}, err
+		}
+		opts.Config.ExposedPorts[docker.Port(port)] = struct{}{}
+	}
+
 	for _, vol := range srv.Volumes {
 		if !strings.Contains(vol, ":") {
 			continue
@@ -1068,7 +1259,63 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 		opts.Config.Volumes[strings.Split(vol, ":")[1]] = struct{}{}
 	}
 
-	return opts
+	return opts, nil
+}
+
+// exposedPort validates the expose entry from the definition file
+// ("8080", "8080/tcp", "53/udp") and returns it with the protocol tag.
+func exposedPort(entry string) (string, error) {
+	port := util.PortAndProtocol(strings.TrimSpace(entry))
+
+	spl := strings.Split(port, "/")
+	if len(spl) != 2 {
+		return "", fmt.Errorf("Invalid expose entry %q: expecting <port>[/tcp|/udp]", entry)
+	}
+	if n, err := strconv.Atoi(spl[0]); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("Invalid expose entry %q: port should be a number between 1 and 65535", entry)
+	}
+	if spl[1] != "tcp" && spl[1] != "udp" {
+		return "", fmt.Errorf("Invalid expose entry %q: protocol should be either tcp or udp", entry)
+	}
+
+	return port, nil
+}
+
+// checkPIDMode validates the pid field of the definition file.
+// Empty value (private PID namespace), "host" and "container:<name>"
+// are allowed.
+func checkPIDMode(mode string) error {
+	switch {
+	case mode == "", mode == "host":
+		return nil
+	case strings.HasPrefix(mode, "container:") && strings.TrimPrefix(mode, "container:") != "":
+		return nil
+	}
+	return fmt.Errorf(`Invalid pid mode %q: expecting "host" or "container:<name>"`, mode)
+}
+
+// checkNetworkMode validates the net field of the definition file.
+// It accepts the "bridge", "host", "none", "default" modes, the
+// "container:<name>" mode, or a user defined network name. Links are
+// refused for the "host" and "container:<name>" modes.
+func checkNetworkMode(mode string, links []string) error {
+	if mode == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(mode, "container:") {
+		if strings.TrimPrefix(mode, "container:") == "" {
+			return fmt.Errorf(`Invalid net mode %q: expecting "container:<name>"`, mode)
+		}
+	} else if !networkName.MatchString(mode) {
+		return fmt.Errorf(`Invalid net mode %q: expecting "bridge", "host", "none", "container:<name>", or a network name`, mode)
+	}
+
+	if (mode == "host" || strings.HasPrefix(mode, "container:")) && len(links) != 0 {
+		return fmt.Errorf("Net mode %q cannot be used with links (%s). Remove the links or the dependencies from the definition file", mode, strings.Join(links, ", "))
+	}
+
+	return nil
 }
 
 func configureVolumesFromContainer(ops *def.Operation, service *def.Service) docker.CreateContainerOptions {
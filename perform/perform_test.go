@@ -2,6 +2,7 @@ package perform
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 	"github.com/eris-ltd/eris-cli/util"
 
 	log "github.com/eris-ltd/eris-logger"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 func TestMain(m *testing.M) {
@@ -299,6 +301,92 @@ func TestRunServiceNonExistentImage(t *testing.T) {
 	}
 }
 
+func TestRunServiceEnvFileExposePIDNet(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	envFile := filepath.Join(os.TempDir(), "test.env")
+	if err := ioutil.WriteFile(envFile, []byte("# comment\nFROM_FILE=1\n\nOVERRIDDEN=file\n"), 0644); err != nil {
+		t.Fatalf("could not write env file: %v", err)
+	}
+	defer os.Remove(envFile)
+
+	srv, err := loaders.LoadServiceDefinition(name)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.EnvFile = []string{envFile}
+	srv.Service.Environment = []string{"OVERRIDDEN=environment"}
+	srv.Service.Expose = []string{"7000", "7001/udp"}
+	srv.Service.PID = "host"
+	srv.Service.Net = "bridge"
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	container, err := util.DockerClient.InspectContainer(srv.Operations.SrvContainerName)
+	if err != nil {
+		t.Fatalf("expected service container inspected, got %v", err)
+	}
+
+	env := strings.Join(container.Config.Env, " ")
+	if !strings.Contains(env, "FROM_FILE=1") || !strings.Contains(env, "OVERRIDDEN=environment") {
+		t.Fatalf("expected environment set from env file, got %v", container.Config.Env)
+	}
+	if strings.Index(env, "OVERRIDDEN=file") > strings.Index(env, "OVERRIDDEN=environment") {
+		t.Fatalf("expected environment field to take precedence, got %v", container.Config.Env)
+	}
+	for _, port := range []docker.Port{"7000/tcp", "7001/udp"} {
+		if _, ok := container.Config.ExposedPorts[port]; !ok {
+			t.Fatalf("expected port %v exposed, got %v", port, container.Config.ExposedPorts)
+		}
+	}
+	if container.HostConfig.PidMode != "host" {
+		t.Fatalf("expected pid mode host, got %q", container.HostConfig.PidMode)
+	}
+	if container.HostConfig.NetworkMode != "bridge" {
+		t.Fatalf("expected net mode bridge, got %q", container.HostConfig.NetworkMode)
+	}
+}
+
+func TestRunServiceBadDefinitionValues(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	for _, change := range []func(srv *def.Service){
+		func(srv *def.Service) { srv.EnvFile = []string{"/non/existent/file.env"} },
+		func(srv *def.Service) { srv.Expose = []string{"port"} },
+		func(srv *def.Service) { srv.Expose = []string{"70000"} },
+		func(srv *def.Service) { srv.Expose = []string{"7000/sctp"} },
+		func(srv *def.Service) { srv.PID = "private" },
+		func(srv *def.Service) { srv.PID = "container:" },
+		func(srv *def.Service) { srv.Net = "bad net" },
+		func(srv *def.Service) { srv.Net = "container:" },
+		func(srv *def.Service) { srv.Net = "host"; srv.Links = []string{"eris_service_keys_1:keys"} },
+		func(srv *def.Service) { srv.Restart = "max:many" },
+	} {
+		srv, err := loaders.LoadServiceDefinition(name)
+		if err != nil {
+			t.Fatalf("could not load service definition %v", err)
+		}
+
+		change(srv.Service)
+		if err := DockerRunService(srv.Service, srv.Operations); err == nil {
+			t.Fatalf("expected run service to fail")
+		}
+		if util.Exists(def.TypeService, name) {
+			t.Fatalf("expecting service container doesn't exist")
+		}
+	}
+}
+
 func TestExecServiceSimple(t *testing.T) {
 	const (
 		name = "ipfs"
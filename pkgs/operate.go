@@ -73,7 +73,8 @@ func RunPackage(do *definitions.Do) error {
 	return CleanUp(do, pkg)
 }
 
-// ensures that dependent services are started and that the appropriate chain is booted
+// ensures that the appropriate chain is booted and that dependent services are started
+// (services come second so that they can be connected to the booted chain)
 //
 //  do.ServicesSlice - slice of dependent services to boot before the eris-pm runs
 //  do.ChainName - name of the chain to ensure is booted (if "" then will check the checkedout chain)
@@ -84,25 +85,8 @@ func RunPackage(do *definitions.Do) error {
 //
 func BootServicesAndChain(do *definitions.Do, pkg *definitions.Package) error {
 	var err error
-	var srvs []*definitions.ServiceDefinition
 	do.ServicesSlice = append(do.ServicesSlice, pkg.Dependencies.Services...)
 
-	// assemble the services
-	for _, s := range do.ServicesSlice {
-		t, err := services.BuildServicesGroup(s, srvs...)
-		if err != nil {
-			return err
-		}
-		srvs = append(srvs, t...)
-	}
-
-	// boot the services
-	if len(srvs) >= 1 {
-		if err := services.StartGroup(srvs); err != nil {
-			return err
-		}
-	}
-
 	// overwrite do.ChainName with pkg.ChainName if do.ChainName blank
 	if do.ChainName == "" {
 		do.ChainName = pkg.ChainName
@@ -133,6 +117,35 @@ func BootServicesAndChain(do *definitions.Do, pkg *definitions.Package) error {
 		return err
 	}
 
+	// The package services shouldn't race the chain.
+	if err := waitForChain(do); err != nil {
+		return err
+	}
+
+	// Services which use the `$chain` variable are connected to
+	// the package chain (unless that chain is run as a service).
+	chainFlag := do.Chain.Name
+	if do.Chain.ChainType == "service" {
+		chainFlag = ""
+	}
+
+	// assemble the services
+	graph, err := services.BuildDependencyGraph(chainFlag, do.ServicesSlice...)
+	if err != nil {
+		return err
+	}
+	levels, err := graph.Levels()
+	if err != nil {
+		return err
+	}
+
+	// boot the services
+	if len(levels) >= 1 {
+		if err := services.StartGroup(levels); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -140,15 +153,19 @@ func BootServicesAndChain(do *definitions.Do, pkg *definitions.Package) error {
 //
 //  do.Name      - name. [csk] unused?
 //  do.Path      - pkg root path
-//  do.ChainPort - port number (as a string) to chain's RPC
+//  do.ChainPort - port number (as a string) to chain's RPC (defaults to
+//                 the "rpc" endpoint of the chain definition)
 //  do.KeysPort  - port number (as a string) to eris-keys signing pipe
+//                 (defaults to the "api" endpoint of the keys definition)
 //  pkg.Name     - name [csk, why do we have two?]; defaults to dirName(".")
 //
 func DefinePkgActionService(do *definitions.Do, pkg *definitions.Package) error {
+	resolvePorts(do)
+
 	do.Service.Name = pkg.Name + "_tmp_" + do.Name
 	do.Service.Image = path.Join(version.ERIS_REG_DEF, version.ERIS_IMG_PM)
 	do.Service.AutoData = true
-	do.Service.EntryPoint = fmt.Sprintf("epm --chain chain:%s --sign keys:%s", do.ChainPort, do.KeysPort)
+	do.Service.EntryPoint = fmt.Sprintf("%s --chain chain:%s --sign keys:%s", definitions.EPMApp().EntryPoint, do.ChainPort, do.KeysPort)
 	do.Service.WorkDir = path.Join(common.ErisContainerRoot, "apps", filepath.Base(do.Path))
 	do.Service.User = "eris"
 
@@ -302,8 +319,14 @@ func bootChain(name string, do *definitions.Do) error {
 	do.Chain.Name = name // setting this for tear down purposes
 
 	// let the chain boot properly
-	time.Sleep(5 * time.Second)
-	return nil
+	if do.Chain.ChainType == "service" {
+		srv, err := loaders.LoadServiceDefinition(name)
+		if err != nil {
+			return err
+		}
+		return perform.DockerWaitChainReady(srv.Service, srv.Operations, chains.DefaultWaitTimeout*time.Second)
+	}
+	return chains.WaitChain(startChain)
 }
 
 // if a throwaway chain is noted; booth that chain
@@ -324,13 +347,74 @@ func bootThrowAwayChain(name string, do *definitions.Do) error {
 	do.Chain.Name = do.Name // setting this for tear down purposes
 	log.WithField("=>", do.Name).Debug("Throwaway chain booted")
 
-	// let the chain boot properly
-	time.Sleep(5 * time.Second)
-
 	do.Name = tmp
 	return nil
 }
 
+// waitForChain waits until the booted chain (do.Chain) passes
+// the health check from its definition, if there's one.
+func waitForChain(do *definitions.Do) error {
+	var (
+		chain *definitions.ServiceDefinition
+		err   error
+	)
+	if do.Chain.ChainType == "service" {
+		chain, err = loaders.LoadServiceDefinition(do.Chain.Name)
+	} else {
+		chain, err = loaders.ChainsAsAService(do.Chain.Name)
+	}
+	if err != nil {
+		log.WithField("=>", do.Chain.Name).Debug("No chain definition to look up the health check in")
+		return nil
+	}
+
+	return perform.DockerWaitHealthy(chain.Service, chain.Operations)
+}
+
+// Ports used if the chain or the keys definition can't be loaded.
+const (
+	defaultChainPort = "46657"
+	defaultKeysPort  = "4767"
+)
+
+// resolvePorts fills in the blank do.ChainPort and do.KeysPort from
+// the endpoints of the chain (do.Chain) and the keys definitions.
+func resolvePorts(do *definitions.Do) {
+	if do.ChainPort == "" {
+		do.ChainPort = defaultChainPort
+
+		var (
+			chain *definitions.ServiceDefinition
+			err   error
+		)
+		if do.Chain.ChainType == "service" {
+			chain, err = loaders.LoadServiceDefinition(do.Chain.Name)
+		} else {
+			chain, err = loaders.ChainsAsAService(do.Chain.Name)
+		}
+		if err == nil {
+			if port, err := util.EndpointPort(chain.Service, definitions.EndpointRPC); err == nil {
+				do.ChainPort = port
+			}
+		}
+	}
+
+	if do.KeysPort == "" {
+		do.KeysPort = defaultKeysPort
+
+		if keys, err := loaders.LoadServiceDefinition("keys"); err == nil {
+			if port, err := util.EndpointPort(keys.Service, definitions.EndpointAPI); err == nil {
+				do.KeysPort = port
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"chain port": do.ChainPort,
+		"keys port":  do.KeysPort,
+	}).Debug("Resolved pkg action ports")
+}
+
 // ensures chain properly connected to eris-pm services container. assumes a do and pkg struct properly populated
 func linkAppToChain(do *definitions.Do, pkg *definitions.Package) {
 	var newLink string
@@ -0,0 +1,216 @@
+// Package rpc is a client for the chain RPC server (the JSON-over-HTTP
+// interface Tendermint exposes on the "rpc" endpoint of the chain container).
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout limits the time of a single RPC request.
+const DefaultTimeout = 10 * time.Second
+
+// Client sends requests to the chain RPC server.
+type Client struct {
+	// Address is the host:port address of the RPC server.
+	Address string
+
+	// Timeout limits the time of a single request.
+	Timeout time.Duration
+}
+
+// NewClient returns a client of the RPC server at the host:port address.
+func NewClient(address string) *Client {
+	return &Client{
+		Address: address,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Status returns the node status.
+func (c *Client) Status() (*Status, error) {
+	status := new(Status)
+	if err := c.Call("status", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// NetInfo returns the node network information and peers.
+func (c *Client) NetInfo() (*NetInfo, error) {
+	info := new(NetInfo)
+	if err := c.Call("net_info", nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Validators returns the current validator set.
+func (c *Client) Validators() (*Validators, error) {
+	validators := new(Validators)
+	if err := c.Call("list_validators", nil, validators); err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
+// Genesis returns the genesis document the node was started with.
+func (c *Client) Genesis() (*Genesis, error) {
+	var result struct {
+		Genesis *Genesis `json:"genesis"`
+	}
+	if err := c.Call("genesis", nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Genesis == nil {
+		return nil, fmt.Errorf("Chain returned no genesis document")
+	}
+	return result.Genesis, nil
+}
+
+// Block returns the block at the height.
+func (c *Client) Block(height int) (*BlockResult, error) {
+	block := new(BlockResult)
+	if err := c.Call("get_block", url.Values{"height": {strconv.Itoa(height)}}, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Tx returns the transaction with the hex encoded hash.
+func (c *Client) Tx(hash string) (map[string]interface{}, error) {
+	hash, err := hexParam(hash)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid transaction hash: %v", err)
+	}
+
+	tx := make(map[string]interface{})
+	if err := c.Call("tx", url.Values{"hash": {hash}}, &tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Account returns the account with the hex encoded address.
+func (c *Client) Account(address string) (*Account, error) {
+	address, err := hexParam(address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid account address: %v", err)
+	}
+
+	var result struct {
+		Account *Account `json:"account"`
+	}
+	if err := c.Call("get_account", url.Values{"address": {address}}, &result); err != nil {
+		return nil, err
+	}
+	if result.Account == nil {
+		return nil, fmt.Errorf("Account %s does not exist", strings.TrimPrefix(address, "0x"))
+	}
+	return result.Account, nil
+}
+
+// Storage returns the value stored under the hex encoded key
+// in the storage of the contract with the hex encoded address.
+func (c *Client) Storage(address, key string) (*Storage, error) {
+	address, err := hexParam(address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid account address: %v", err)
+	}
+	key, err = hexParam(key)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid storage key: %v", err)
+	}
+
+	storage := new(Storage)
+	if err := c.Call("get_storage", url.Values{"address": {address}, "key": {key}}, storage); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// Call sends the request for the method to the RPC server (using the URI
+// interface, i.e. http://ADDRESS/METHOD?PARAMS) and decodes the result
+// into v.
+func (c *Client) Call(method string, params url.Values, v interface{}) error {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	address := "http://" + strings.TrimPrefix(c.Address, "http://") + "/" + method
+	if len(params) > 0 {
+		address += "?" + params.Encode()
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(address)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET %s returned %s", address, resp.Status)
+		}
+		return fmt.Errorf("Cannot read the %s response: %v", method, err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("Chain returned an error to %s: %s", method, response.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", address, resp.Status)
+	}
+
+	result, err := unwrap(response.Result)
+	if err != nil {
+		return fmt.Errorf("Cannot read the %s response: %v", method, err)
+	}
+	if err := json.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("Cannot read the %s response: %v", method, err)
+	}
+	return nil
+}
+
+// hexParam validates the hex encoded value and
+// returns it in the form the RPC server expects.
+func hexParam(value string) (string, error) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	if value == "" {
+		return "", fmt.Errorf("empty value")
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return "", fmt.Errorf("%q is not a hex string", value)
+	}
+	return "0x" + strings.ToUpper(value), nil
+}
+
+// unwrap strips the [type, object] pair older
+// RPC servers wrap the results into.
+func unwrap(result json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(result)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, fmt.Errorf("empty result")
+	}
+	if trimmed[0] != '[' {
+		return trimmed, nil
+	}
+
+	var pair []json.RawMessage
+	if err := json.Unmarshal(trimmed, &pair); err != nil || len(pair) != 2 {
+		return nil, fmt.Errorf("unexpected result %s", trimmed)
+	}
+	return pair[1], nil
+}
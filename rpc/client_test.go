@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/tests"
+)
+
+func newTestClient(response string) (*Client, *tests.Server) {
+	server := tests.NewServer()
+	server.SetResponse(tests.ServerResponse{
+		Code: http.StatusOK,
+		Body: response,
+	})
+	return NewClient(strings.TrimPrefix(server.URL(), "http://")), server
+}
+
+var StatusTests = []struct {
+	response string
+	height   int
+	err      bool
+}{
+	{`{"jsonrpc":"2.0","id":"","result":[32,{"latest_block_height":12,"latest_block_hash":"AB12"}],"error":""}`, 12, false},
+	{`{"jsonrpc":"2.0","id":"","result":{"latest_block_height":3}}`, 3, false},
+	{`{"jsonrpc":"2.0","id":"","result":null,"error":"not ready"}`, 0, true},
+	{`{"jsonrpc":"2.0","id":"","result":[32]}`, 0, true},
+	{`garbage`, 0, true},
+}
+
+func TestStatus(t *testing.T) {
+	for _, test := range StatusTests {
+		client, server := newTestClient(test.response)
+
+		status, err := client.Status()
+		server.Close()
+		if test.err {
+			if err == nil {
+				t.Fatalf("expected %s to fail", test.response)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expected %s to pass, got %v", test.response, err)
+		}
+		if server.Path() != "/status" {
+			t.Fatalf("expected the /status endpoint, got %q", server.Path())
+		}
+		if status.LatestBlockHeight != test.height {
+			t.Fatalf("expected height %d, got %d", test.height, status.LatestBlockHeight)
+		}
+	}
+}
+
+func TestValidators(t *testing.T) {
+	client, server := newTestClient(`{"jsonrpc":"2.0","id":"","result":[20,{"block_height":7,"bonded_validators":[{"address":"AA","pub_key":[1,"PUB1"],"voting_power":10},{"address":"BB","pub_key":{"type":"ed25519","data":"PUB2"},"voting_power":5}],"unbonding_validators":[]}],"error":""}`)
+	defer server.Close()
+
+	validators, err := client.Validators()
+	if err != nil {
+		t.Fatalf("expected validators, got %v", err)
+	}
+	if server.Path() != "/list_validators" {
+		t.Fatalf("expected the /list_validators endpoint, got %q", server.Path())
+	}
+	if validators.BlockHeight != 7 || len(validators.BondedValidators) != 2 {
+		t.Fatalf("unexpected validators %+v", validators)
+	}
+	for i, expected := range []string{"PUB1", "PUB2"} {
+		if key := validators.BondedValidators[i].PubKey.String(); key != expected {
+			t.Fatalf("expected public key %q, got %q", expected, key)
+		}
+	}
+}
+
+func TestNetInfo(t *testing.T) {
+	client, server := newTestClient(`{"jsonrpc":"2.0","id":"","result":{"listening":true,"listeners":["Listener(@1.2.3.4:46656)"],"peers":[{"node_info":{"moniker":"one","remote_addr":"5.6.7.8:46656"},"is_outbound":true}]}}`)
+	defer server.Close()
+
+	info, err := client.NetInfo()
+	if err != nil {
+		t.Fatalf("expected net info, got %v", err)
+	}
+	if !info.Listening || len(info.Peers) != 1 || info.Peers[0].NodeInfo.Moniker != "one" || !info.Peers[0].IsOutbound {
+		t.Fatalf("unexpected net info %+v", info)
+	}
+}
+
+func TestAccount(t *testing.T) {
+	client, server := newTestClient(`{"jsonrpc":"2.0","id":"","result":[2,{"account":{"address":"1A2B","pub_key":null,"sequence":3,"balance":100,"code":"","storage_root":""}}],"error":""}`)
+	defer server.Close()
+
+	account, err := client.Account("0x1a2b")
+	if err != nil {
+		t.Fatalf("expected account, got %v", err)
+	}
+	if server.Path() != "/get_account" {
+		t.Fatalf("expected the /get_account endpoint, got %q", server.Path())
+	}
+	if account.Balance != 100 || account.Sequence != 3 {
+		t.Fatalf("unexpected account %+v", account)
+	}
+
+	if _, err := client.Account("not hex"); err == nil {
+		t.Fatalf("expected an invalid address to fail")
+	}
+}
+
+func TestHexParam(t *testing.T) {
+	for _, test := range []struct {
+		in, out string
+		err     bool
+	}{
+		{"1a2b", "0x1A2B", false},
+		{"0x1A2B", "0x1A2B", false},
+		{"", "", true},
+		{"0x", "", true},
+		{"xyz", "", true},
+		{"123", "", true},
+	} {
+		out, err := hexParam(test.in)
+		if test.err {
+			if err == nil {
+				t.Fatalf("expected %q to fail", test.in)
+			}
+			continue
+		}
+		if err != nil || out != test.out {
+			t.Fatalf("expected %q, got %q (%v)", test.out, out, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Status is the result of the status request.
+type Status struct {
+	NodeInfo          *NodeInfo `json:"node_info"`
+	GenesisHash       string    `json:"genesis_hash"`
+	PubKey            PubKey    `json:"pub_key"`
+	LatestBlockHash   string    `json:"latest_block_hash"`
+	LatestBlockHeight int       `json:"latest_block_height"`
+	LatestBlockTime   int64     `json:"latest_block_time"` // nanoseconds
+}
+
+// BlockTime returns the time of the latest block.
+func (s *Status) BlockTime() time.Time {
+	return time.Unix(0, s.LatestBlockTime)
+}
+
+// NodeInfo describes a chain node.
+type NodeInfo struct {
+	PubKey     PubKey   `json:"pub_key"`
+	Moniker    string   `json:"moniker"`
+	Network    string   `json:"network"`
+	RemoteAddr string   `json:"remote_addr"`
+	ListenAddr string   `json:"listen_addr"`
+	Version    string   `json:"version"`
+	Other      []string `json:"other"`
+}
+
+// NetInfo is the result of the net_info request.
+type NetInfo struct {
+	Listening bool     `json:"listening"`
+	Listeners []string `json:"listeners"`
+	Peers     []*Peer  `json:"peers"`
+}
+
+// Peer is a node the chain node is connected to.
+type Peer struct {
+	NodeInfo   *NodeInfo `json:"node_info"`
+	IsOutbound bool      `json:"is_outbound"`
+}
+
+// Validators is the result of the list_validators request.
+type Validators struct {
+	BlockHeight         int          `json:"block_height"`
+	BondedValidators    []*Validator `json:"bonded_validators"`
+	UnbondingValidators []*Validator `json:"unbonding_validators"`
+}
+
+// Validator is a member of the validator set.
+type Validator struct {
+	Address          string `json:"address"`
+	PubKey           PubKey `json:"pub_key"`
+	BondHeight       int    `json:"bond_height"`
+	UnbondHeight     int    `json:"unbond_height"`
+	LastCommitHeight int    `json:"last_commit_height"`
+	VotingPower      int64  `json:"voting_power"`
+	Accum            int64  `json:"accum"`
+}
+
+// Genesis is the genesis document of the chain.
+type Genesis struct {
+	GenesisTime string              `json:"genesis_time"`
+	ChainID     string              `json:"chain_id"`
+	Params      json.RawMessage     `json:"params,omitempty"`
+	Accounts    []*GenesisAccount   `json:"accounts"`
+	Validators  []*GenesisValidator `json:"validators"`
+}
+
+// GenesisAccount is an account created with the chain.
+type GenesisAccount struct {
+	Address     string          `json:"address"`
+	Amount      int64           `json:"amount"`
+	Name        string          `json:"name"`
+	Permissions json.RawMessage `json:"permissions,omitempty"`
+}
+
+// GenesisValidator is a validator the chain started with.
+type GenesisValidator struct {
+	PubKey   PubKey            `json:"pub_key"`
+	Amount   int64             `json:"amount"`
+	Name     string            `json:"name"`
+	UnbondTo []*GenesisAccount `json:"unbond_to"`
+}
+
+// BlockResult is the result of the get_block request.
+type BlockResult struct {
+	BlockMeta *BlockMeta `json:"block_meta"`
+	Block     *Block     `json:"block"`
+}
+
+// BlockMeta holds the block hash and header.
+type BlockMeta struct {
+	Hash   string  `json:"hash"`
+	Header *Header `json:"header"`
+}
+
+// Block is a block of the chain.
+type Block struct {
+	Header *Header `json:"header"`
+	Data   struct {
+		Txs []json.RawMessage `json:"txs"`
+	} `json:"data"`
+	LastValidation json.RawMessage `json:"last_validation,omitempty"`
+}
+
+// Header is a block header.
+type Header struct {
+	ChainID            string      `json:"chain_id"`
+	Height             int         `json:"height"`
+	Time               interface{} `json:"time"`
+	NumTxs             int         `json:"num_txs"`
+	LastBlockHash      string      `json:"last_block_hash"`
+	LastValidationHash string      `json:"last_validation_hash"`
+	DataHash           string      `json:"data_hash"`
+	StateHash          string      `json:"state_hash"`
+}
+
+// Account is a chain account or a contract.
+type Account struct {
+	Address     string          `json:"address"`
+	PubKey      PubKey          `json:"pub_key"`
+	Sequence    int             `json:"sequence"`
+	Balance     int64           `json:"balance"`
+	Code        string          `json:"code"`
+	StorageRoot string          `json:"storage_root"`
+	Permissions json.RawMessage `json:"permissions,omitempty"`
+}
+
+// Storage is the result of the get_storage request.
+type Storage struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// PubKey is a public key encoded either as a [type, "HEX"] pair
+// or a {"type": type, "data": "HEX"} object.
+type PubKey json.RawMessage
+
+// String returns the hex encoded key.
+func (k PubKey) String() string {
+	var pair []interface{}
+	if err := json.Unmarshal(k, &pair); err == nil && len(pair) == 2 {
+		if key, ok := pair[1].(string); ok {
+			return key
+		}
+	}
+
+	var object struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(k, &object); err == nil && object.Data != "" {
+		return object.Data
+	}
+	return strings.Trim(string(k), `"`)
+}
+
+// MarshalJSON returns the key as it was received.
+func (k PubKey) MarshalJSON() ([]byte, error) {
+	if len(k) == 0 {
+		return []byte("null"), nil
+	}
+	return k, nil
+}
+
+// UnmarshalJSON stores the raw key.
+func (k *PubKey) UnmarshalJSON(data []byte) error {
+	*k = append((*k)[0:0], data...)
+	return nil
+}
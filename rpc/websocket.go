@@ -0,0 +1,362 @@
+package rpc
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Websocket frame opcodes (RFC 6455).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// Maximum size of a websocket message.
+	maxMessageSize = 16 << 20
+
+	// The connection is pinged every pingInterval and considered
+	// dead if nothing arrives from the server for readTimeout.
+	pingInterval = 10 * time.Second
+	readTimeout  = 3 * pingInterval
+)
+
+// Websocket close codes (RFC 6455).
+const (
+	CloseNormal    = 1000
+	CloseGoingAway = 1001
+	CloseNoStatus  = 1005
+)
+
+// CloseError is returned when the server closes the websocket connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed by the server (code %d)", e.Code)
+	}
+	return fmt.Sprintf("websocket closed by the server (code %d): %s", e.Code, e.Reason)
+}
+
+// ConnectionLostError is returned by Subscribe when an established
+// subscription ends, e.g. because the chain node was restarted.
+type ConnectionLostError struct {
+	Err error
+}
+
+func (e *ConnectionLostError) Error() string {
+	return fmt.Sprintf("connection lost: %v", e.Err)
+}
+
+// Event is a chain event received over the websocket connection.
+type Event struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Subscribe connects to the websocket endpoint of the RPC server,
+// subscribes to the events (e.g. "NewBlock" or "Log/ADDRESS") and calls
+// fn for every received event. Subscribe returns when the connection
+// fails or fn returns an error. Once subscribed, connection failures
+// are returned as a *ConnectionLostError.
+func (c *Client) Subscribe(events []string, fn func(*Event) error) error {
+	conn, err := dialWebsocket(c.Address, c.Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, event := range events {
+		request, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      "",
+			"method":  "subscribe",
+			"params":  []string{event},
+		})
+		if err != nil {
+			return err
+		}
+		if err := conn.writeFrame(opText, request); err != nil {
+			return err
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.writeFrame(opPing, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		message, err := conn.readMessage()
+		if err != nil {
+			return &ConnectionLostError{Err: err}
+		}
+
+		var response struct {
+			Result json.RawMessage `json:"result"`
+			Error  string          `json:"error"`
+		}
+		if err := json.Unmarshal(message, &response); err != nil {
+			return fmt.Errorf("Cannot read the event: %v", err)
+		}
+		if response.Error != "" {
+			return fmt.Errorf("Chain returned an error to subscribe: %s", response.Error)
+		}
+
+		// Subscription confirmations carry no event.
+		result, err := unwrap(response.Result)
+		if err != nil {
+			continue
+		}
+		event := new(Event)
+		if err := json.Unmarshal(result, event); err != nil || event.Event == "" {
+			continue
+		}
+		if data, err := unwrap(event.Data); err == nil {
+			event.Data = data
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// wsConn is a client websocket connection.
+type wsConn struct {
+	net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex // guards writes
+}
+
+// dialWebsocket opens the websocket connection to the
+// ws://ADDRESS/websocket endpoint of the RPC server.
+func dialWebsocket(address string, timeout time.Duration) (*wsConn, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	address = strings.TrimPrefix(address, "http://")
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request, err := http.NewRequest("GET", "http://"+address+"/websocket", nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	request.Header.Set("Sec-WebSocket-Version", "13")
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("GET %s/websocket returned %s", address, response.Status)
+	}
+	if response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("Invalid websocket handshake response from %s", address)
+	}
+	conn.SetDeadline(time.Time{})
+
+	return &wsConn{Conn: conn, reader: reader}, nil
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value
+// the server should answer the key with.
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// writeFrame sends a single masked frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, 0x80|byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(rand.Reader, mask); err != nil {
+		return err
+	}
+	header = append(header, mask...)
+
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+
+	c.SetWriteDeadline(time.Now().Add(readTimeout))
+	_, err := c.Write(append(header, masked...))
+	return err
+}
+
+// readMessage returns the next text or binary message (reassembled
+// from its fragments), answering pings on the way. It returns
+// a *CloseError if the server closes the connection.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var (
+		message    []byte
+		fragmented bool
+	)
+	for {
+		c.SetReadDeadline(time.Now().Add(readTimeout))
+
+		opcode, fin, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		// Control frames can be interleaved with the fragments
+		// of a message, but are never fragmented themselves.
+		if opcode >= opClose && (!fin || len(payload) > 125) {
+			return nil, fmt.Errorf("invalid websocket control frame %#x", opcode)
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+				// Echo the status code back as the closing handshake.
+				c.writeFrame(opClose, payload[:2])
+			} else {
+				c.writeFrame(opClose, nil)
+			}
+			return nil, closeErr
+		case opText, opBinary, opContinuation:
+			if (opcode == opContinuation) != fragmented {
+				return nil, fmt.Errorf("unexpected websocket frame %#x in a fragmented message", opcode)
+			}
+			fragmented = !fin
+			message = append(message, payload...)
+			if len(message) > maxMessageSize {
+				return nil, fmt.Errorf("websocket message is larger than %d bytes", maxMessageSize)
+			}
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unexpected websocket opcode %#x", opcode)
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (opcode byte, fin bool, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(c.reader, header); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(c.reader, ext); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(c.reader, ext); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > maxMessageSize {
+		err = fmt.Errorf("websocket frame is larger than %d bytes", maxMessageSize)
+		return
+	}
+
+	mask := make([]byte, 4)
+	if masked {
+		if _, err = io.ReadFull(c.reader, mask); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveWebsocket answers the websocket handshake, reads the subscribe
+// request and hands the connection over to serve.
+func serveWebsocket(t *testing.T, subscribed chan<- string, serve func(conn net.Conn, ws *wsConn)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/websocket" {
+			http.NotFound(w, r)
+			return
+		}
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("cannot hijack the connection: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		buf.Flush()
+
+		ws := &wsConn{Conn: conn, reader: bufio.NewReader(buf)}
+		_, _, request, err := ws.readFrame()
+		if err != nil {
+			t.Errorf("cannot read the subscribe request: %v", err)
+			return
+		}
+		subscribed <- string(request)
+
+		serve(conn, ws)
+	}))
+}
+
+// serveEvents sends the messages as text frames
+// after the subscribe request before closing.
+func serveEvents(t *testing.T, subscribed chan<- string, messages ...string) *httptest.Server {
+	return serveWebsocket(t, subscribed, func(conn net.Conn, ws *wsConn) {
+		for _, message := range messages {
+			conn.Write(append([]byte{0x80 | opText, byte(len(message))}, message...))
+		}
+		conn.Write([]byte{0x80 | opClose, 0})
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	subscribed := make(chan string, 1)
+	server := serveEvents(t, subscribed,
+		`{"jsonrpc":"2.0","id":"","result":null,"error":""}`,
+		`{"jsonrpc":"2.0","id":"#event","result":[19,{"event":"NewBlock","data":[1,{"block":{}}]}],"error":""}`,
+	)
+	defer server.Close()
+
+	var events []*Event
+	err := NewClient(strings.TrimPrefix(server.URL, "http://")).Subscribe([]string{"NewBlock"}, func(event *Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if lost, ok := err.(*ConnectionLostError); !ok || lost.Err.(*CloseError).Code != CloseNoStatus {
+		t.Fatalf("expected the subscription to be lost with no close status, got %v", err)
+	}
+
+	if request := <-subscribed; !strings.Contains(request, `"method":"subscribe"`) || !strings.Contains(request, `"params":["NewBlock"]`) {
+		t.Fatalf("unexpected subscribe request %s", request)
+	}
+	if len(events) != 1 || events[0].Event != "NewBlock" || string(events[0].Data) != `{"block":{}}` {
+		t.Fatalf("expected one NewBlock event, got %v", events)
+	}
+}
+
+func TestSubscribeServerClose(t *testing.T) {
+	var (
+		subscribed = make(chan string, 1)
+		replies    = make(chan []byte, 2)
+		event      = `{"jsonrpc":"2.0","id":"#event","result":{"event":"NewBlock","data":{"block":{}}},"error":""}`
+	)
+	server := serveWebsocket(t, subscribed, func(conn net.Conn, ws *wsConn) {
+		// A ping in the middle of a message sent in two fragments.
+		conn.Write(append([]byte{opText, 10}, event[:10]...))
+		conn.Write([]byte{0x80 | opPing, 4, 'p', 'i', 'n', 'g'})
+		conn.Write(append([]byte{0x80 | opContinuation, byte(len(event) - 10)}, event[10:]...))
+		conn.Write([]byte{0x80 | opClose, 9, 0x03, 0xE9, 'r', 'e', 's', 't', 'a', 'r', 't'})
+
+		for i := 0; i < 2; i++ {
+			opcode, _, payload, err := ws.readFrame()
+			if err != nil {
+				t.Errorf("cannot read the reply: %v", err)
+				return
+			}
+			replies <- append([]byte{opcode}, payload...)
+		}
+	})
+	defer server.Close()
+
+	var events []*Event
+	err := NewClient(strings.TrimPrefix(server.URL, "http://")).Subscribe([]string{"NewBlock"}, func(event *Event) error {
+		events = append(events, event)
+		return nil
+	})
+	<-subscribed
+
+	lost, ok := err.(*ConnectionLostError)
+	if !ok {
+		t.Fatalf("expected the subscription to be lost, got %v", err)
+	}
+	if closed, ok := lost.Err.(*CloseError); !ok || closed.Code != CloseGoingAway || closed.Reason != "restart" {
+		t.Fatalf("expected the going away close code, got %v", lost.Err)
+	}
+	if len(events) != 1 || events[0].Event != "NewBlock" {
+		t.Fatalf("expected one NewBlock event, got %v", events)
+	}
+
+	if pong := <-replies; string(pong) != string(append([]byte{opPong}, "ping"...)) {
+		t.Fatalf("expected the ping to be answered, got %q", pong)
+	}
+	if closing := <-replies; string(closing) != string([]byte{opClose, 0x03, 0xE9}) {
+		t.Fatalf("expected the close code to be echoed, got %q", closing)
+	}
+}
@@ -0,0 +1,716 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+
+	units "github.com/docker/go-units"
+	"gopkg.in/yaml.v2"
+)
+
+// composeVersion is the docker-compose file format version
+// ExportCompose writes (2.1 is the first one with health checks).
+const composeVersion = "2.1"
+
+// composeFile is a docker-compose file of the version 2 and above.
+type composeFile struct {
+	Version  string                     `yaml:"version"`
+	Services map[string]*composeService `yaml:"services"`
+}
+
+// composeService holds the docker-compose service keys which have
+// an equivalent in eris service definitions. Other keys are reported
+// and skipped on import.
+type composeService struct {
+	Image         string              `yaml:"image,omitempty"`
+	Command       composeCommand      `yaml:"command,omitempty"`
+	Entrypoint    composeCommand      `yaml:"entrypoint,omitempty"`
+	Restart       string              `yaml:"restart,omitempty"`
+	Links         []string            `yaml:"links,omitempty"`
+	ExternalLinks []string            `yaml:"external_links,omitempty"`
+	DependsOn     composeList         `yaml:"depends_on,omitempty"`
+	Ports         []string            `yaml:"ports,omitempty"`
+	Expose        []string            `yaml:"expose,omitempty"`
+	Volumes       []string            `yaml:"volumes,omitempty"`
+	VolumesFrom   []string            `yaml:"volumes_from,omitempty"`
+	Environment   composeEnvironment  `yaml:"environment,omitempty"`
+	EnvFile       composeList         `yaml:"env_file,omitempty"`
+	Net           string              `yaml:"net,omitempty"`
+	NetworkMode   string              `yaml:"network_mode,omitempty"`
+	PID           string              `yaml:"pid,omitempty"`
+	DNS           composeList         `yaml:"dns,omitempty"`
+	DNSSearch     composeList         `yaml:"dns_search,omitempty"`
+	WorkingDir    string              `yaml:"working_dir,omitempty"`
+	Hostname      string              `yaml:"hostname,omitempty"`
+	Domainname    string              `yaml:"domainname,omitempty"`
+	User          string              `yaml:"user,omitempty"`
+	CPUShares     int64               `yaml:"cpu_shares,omitempty"`
+	MemLimit      string              `yaml:"mem_limit,omitempty"`
+	HealthCheck   *composeHealthCheck `yaml:"healthcheck,omitempty"`
+}
+
+type composeHealthCheck struct {
+	Test     composeCommand `yaml:"test,omitempty"`
+	Interval string         `yaml:"interval,omitempty"`
+	Timeout  string         `yaml:"timeout,omitempty"`
+	Retries  int            `yaml:"retries,omitempty"`
+	Disable  bool           `yaml:"disable,omitempty"`
+}
+
+// composeCommand is a command given either as a string
+// or as a list of arguments.
+type composeCommand []string
+
+func (c *composeCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var command string
+	if err := unmarshal(&command); err == nil {
+		*c = composeCommand{command}
+		return nil
+	}
+	var args []string
+	if err := unmarshal(&args); err != nil {
+		return err
+	}
+	*c = composeCommand(args)
+	return nil
+}
+
+func (c composeCommand) MarshalYAML() (interface{}, error) {
+	if len(c) == 1 {
+		return c[0], nil
+	}
+	return []string(c), nil
+}
+
+// composeList is a list given either as a single string, a list,
+// or a map (the keys of which are the list, as in depends_on
+// with conditions).
+type composeList []string
+
+func (l *composeList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*l = composeList{value}
+		return nil
+	}
+	var values []string
+	if err := unmarshal(&values); err == nil {
+		*l = composeList(values)
+		return nil
+	}
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	for key := range keys {
+		*l = append(*l, key)
+	}
+	sort.Strings(*l)
+	return nil
+}
+
+// composeEnvironment is a list of NAME=VALUE variables
+// given either as a list or as a map.
+type composeEnvironment []string
+
+func (e *composeEnvironment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var values []string
+	if err := unmarshal(&values); err == nil {
+		*e = composeEnvironment(values)
+		return nil
+	}
+	var variables map[string]interface{}
+	if err := unmarshal(&variables); err != nil {
+		return err
+	}
+	for name, value := range variables {
+		if value == nil {
+			*e = append(*e, name)
+		} else {
+			*e = append(*e, fmt.Sprintf("%s=%v", name, value))
+		}
+	}
+	sort.Strings(*e)
+	return nil
+}
+
+// SkippedField is a service field without an equivalent
+// in the other format, reported on import or export.
+type SkippedField struct {
+	Service string
+	Field   string
+}
+
+func (s SkippedField) String() string {
+	return s.Service + ": " + s.Field
+}
+
+// ImportCompose reads a docker-compose file and writes an eris service
+// definition file into ServicesPath for every compose service. The
+// `depends_on`, `links`, and `volumes_from` keys pointing to other services
+// of the compose file become the `dependencies.services` of the definition.
+// Compose keys without an eris equivalent are reported and skipped.
+//
+//  do.Path  - location of the docker-compose file (see loaders.Resolvers) (required)
+//  do.Force - overwrite the existing service definition files (optional)
+//
+func ImportCompose(do *definitions.Do) error {
+	contents, fileName, err := loaders.FetchFile(do.Path)
+	if err != nil {
+		return fmt.Errorf("Cannot read the docker-compose file from %s: %v", do.Path, err)
+	}
+	dir := ""
+	if loaders.LocationScheme(do.Path) == "file" {
+		dir = filepath.Dir(fileName)
+	}
+
+	defs, skipped, err := composeToDefinitions(contents, dir)
+	if err != nil {
+		return err
+	}
+	reportSkipped(skipped, "eris")
+
+	for _, def := range defs {
+		if file := util.GetFileByNameAndType("services", def.Name); file != "" && !do.Force {
+			return fmt.Errorf("The %s service definition %s already exists. Please use the --force flag to overwrite it", def.Name, file)
+		}
+	}
+
+	var maintainer definitions.Maintainer
+	if maintainer.Name, maintainer.Email, err = config.GitConfigUser(); err != nil {
+		// don't return -> field not required
+		log.Debug(err.Error())
+	}
+
+	var names []string
+	for _, def := range defs {
+		*def.Maintainer = maintainer
+
+		log.WithFields(log.Fields{
+			"service": def.Name,
+			"image":   def.Service.Image,
+		}).Warn("Writing service definition file")
+		if err := WriteServiceDefinitionFile(def, filepath.Join(ServicesPath, def.Name+".toml")); err != nil {
+			return err
+		}
+		names = append(names, def.Name)
+	}
+
+	do.Result = strings.Join(names, " ")
+	return nil
+}
+
+// ExportCompose renders a docker-compose file from the service definitions
+// and their dependencies (see DependencyGraph). Chains the services depend
+// on become compose services too, and every data container becomes
+// a compose service the main one mounts with `volumes_from`. Eris fields
+// without a compose equivalent are reported and skipped.
+//
+//  do.Operations.Args - names of the services (required)
+//  do.ChainName       - chain the `$chain` variable of the definitions refers to (optional)
+//  do.Destination     - file to write the docker-compose file to (displayed if not given) (optional)
+//
+func ExportCompose(do *definitions.Do) error {
+	graph, err := BuildDependencyGraph(do.ChainName, do.Operations.Args...)
+	if err != nil {
+		return err
+	}
+	sorted, err := graph.Sorted()
+	if err != nil {
+		return err
+	}
+
+	compose, skipped := definitionsToCompose(sorted)
+	reportSkipped(skipped, "docker-compose")
+
+	out, err := yaml.Marshal(compose)
+	if err != nil {
+		return err
+	}
+	out = append([]byte("# docker-compose file exported by [eris services export-compose]\n\n"), out...)
+
+	if do.Destination == "" {
+		config.GlobalConfig.Writer.Write(out)
+	} else {
+		log.WithField("file", do.Destination).Warn("Writing docker-compose file")
+		if err := ioutil.WriteFile(do.Destination, out, 0644); err != nil {
+			return err
+		}
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// parseCompose reads the services of a docker-compose file (the
+// version 1 format without the `version` key is also understood)
+// and reports the keys composeService doesn't hold.
+func parseCompose(contents []byte) (map[string]*composeService, []SkippedField, error) {
+	var top map[string]interface{}
+	if err := yaml.Unmarshal(contents, &top); err != nil {
+		return nil, nil, fmt.Errorf("The docker-compose file is not a valid YAML document: %v", err)
+	}
+
+	var (
+		services = make(map[string]*composeService)
+		raw      = make(map[string]map[string]interface{})
+		skipped  []SkippedField
+	)
+	if _, ok := top["version"]; ok {
+		file := struct {
+			Services map[string]*composeService
+		}{services}
+		rawFile := struct {
+			Services map[string]map[string]interface{}
+		}{raw}
+		if err := yaml.Unmarshal(contents, &file); err != nil {
+			return nil, nil, fmt.Errorf("Cannot read the docker-compose file: %v", err)
+		}
+		if err := yaml.Unmarshal(contents, &rawFile); err != nil {
+			return nil, nil, fmt.Errorf("Cannot read the docker-compose file: %v", err)
+		}
+		services, raw = file.Services, rawFile.Services
+
+		for key := range top {
+			if key != "version" && key != "services" {
+				skipped = append(skipped, SkippedField{"(top level)", key})
+			}
+		}
+	} else {
+		if err := yaml.Unmarshal(contents, &services); err != nil {
+			return nil, nil, fmt.Errorf("Cannot read the docker-compose file: %v", err)
+		}
+		if err := yaml.Unmarshal(contents, &raw); err != nil {
+			return nil, nil, fmt.Errorf("Cannot read the docker-compose file: %v", err)
+		}
+	}
+	if len(services) == 0 {
+		return nil, nil, fmt.Errorf("The docker-compose file has no services")
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(composeService{})
+	for i := 0; i < t.NumField(); i++ {
+		known[strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]] = true
+	}
+	for name, keys := range raw {
+		for key := range keys {
+			if !known[key] {
+				skipped = append(skipped, SkippedField{name, key})
+			}
+		}
+	}
+	return services, skipped, nil
+}
+
+// composeToDefinitions converts the docker-compose services into service
+// definitions. Relative env_file paths are resolved against the dir
+// (if given).
+func composeToDefinitions(contents []byte, dir string) ([]*definitions.ServiceDefinition, []SkippedField, error) {
+	services, skipped, err := parseCompose(contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var defs []*definitions.ServiceDefinition
+	for _, name := range names {
+		def, skip, err := composeToDefinition(name, services[name], services, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		defs = append(defs, def)
+		skipped = append(skipped, skip...)
+	}
+
+	sort.Sort(skippedFields(skipped))
+	return defs, skipped, nil
+}
+
+// composeDependency is a compose service another one depends on.
+type composeDependency struct {
+	name  string
+	alias string
+	mount bool
+}
+
+func composeToDefinition(name string, c *composeService, services map[string]*composeService, dir string) (*definitions.ServiceDefinition, []SkippedField, error) {
+	if c.Image == "" {
+		return nil, nil, fmt.Errorf("The %s compose service has no image. Please build the image and add the `image` key", name)
+	}
+
+	var skipped []SkippedField
+	skip := func(field string) {
+		skipped = append(skipped, SkippedField{name, field})
+	}
+
+	def := definitions.BlankServiceDefinition()
+	def.Name = name
+	srv := def.Service
+	srv.Name = name
+	srv.Image = c.Image
+
+	var ok bool
+	if srv.Command, ok = joinCommand(c.Command); !ok {
+		skip("command (arguments with spaces)")
+	}
+	if srv.EntryPoint, ok = joinCommand(c.Entrypoint); !ok {
+		skip("entrypoint (arguments with spaces)")
+	}
+
+	switch restart := c.Restart; {
+	case restart == "" || restart == "no":
+	case restart == "always":
+		srv.Restart = "always"
+	case restart == "on-failure":
+		srv.Restart = "max:0"
+	case strings.HasPrefix(restart, "on-failure:"):
+		srv.Restart = "max:" + strings.TrimPrefix(restart, "on-failure:")
+	default:
+		skip("restart " + restart)
+	}
+
+	// Links and volumes from other compose services become dependencies,
+	// the rest points to containers outside of the compose file.
+	var deps []*composeDependency
+	dependency := func(service string) *composeDependency {
+		for _, dep := range deps {
+			if dep.name == service {
+				return dep
+			}
+		}
+		dep := &composeDependency{name: service, alias: service}
+		deps = append(deps, dep)
+		return dep
+	}
+	for _, service := range c.DependsOn {
+		dependency(service)
+	}
+	for _, link := range c.Links {
+		spl := strings.SplitN(link, ":", 2)
+		if services[spl[0]] == nil {
+			srv.Links = append(srv.Links, link)
+			continue
+		}
+		dep := dependency(spl[0])
+		if len(spl) > 1 {
+			dep.alias = spl[1]
+		}
+	}
+	srv.Links = append(srv.Links, c.ExternalLinks...)
+	for _, volumes := range c.VolumesFrom {
+		if strings.HasPrefix(volumes, "container:") {
+			srv.VolumesFrom = append(srv.VolumesFrom, strings.TrimPrefix(volumes, "container:"))
+			continue
+		}
+		spl := strings.SplitN(strings.TrimPrefix(volumes, "service:"), ":", 2)
+		if services[spl[0]] == nil {
+			srv.VolumesFrom = append(srv.VolumesFrom, volumes)
+			continue
+		}
+		if len(spl) > 1 && spl[1] == "ro" {
+			skip("volumes_from " + volumes + " (read-only)")
+		}
+		dependency(spl[0]).mount = true
+	}
+	if len(deps) != 0 {
+		def.Dependencies = &definitions.Dependencies{}
+	}
+	for _, dep := range deps {
+		if services[dep.name] == nil {
+			return nil, nil, fmt.Errorf("The %s compose service depends on the unknown %s service", name, dep.name)
+		}
+		// Linked always, since compose services reach each other by name.
+		entry := dep.name + ":" + dep.alias + ":l"
+		if dep.mount {
+			entry = dep.name + ":" + dep.alias
+		}
+		def.Dependencies.Services = append(def.Dependencies.Services, entry)
+	}
+
+	srv.Ports = c.Ports
+	srv.Expose = c.Expose
+	srv.Volumes = c.Volumes
+	srv.Environment = c.Environment
+	for _, file := range c.EnvFile {
+		if dir != "" && !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		srv.EnvFile = append(srv.EnvFile, file)
+	}
+	srv.Net = c.NetworkMode
+	if srv.Net == "" {
+		srv.Net = c.Net
+	}
+	srv.PID = c.PID
+	srv.DNS = c.DNS
+	srv.DNSSearch = c.DNSSearch
+	srv.WorkDir = c.WorkingDir
+	srv.HostName = c.Hostname
+	srv.DomainName = c.Domainname
+	srv.User = c.User
+	srv.CPUShares = c.CPUShares
+	if c.MemLimit != "" {
+		if limit, err := units.RAMInBytes(c.MemLimit); err == nil {
+			srv.MemLimit = limit
+		} else {
+			skip("mem_limit " + c.MemLimit)
+		}
+	}
+
+	if hc := c.HealthCheck; hc != nil && !hc.Disable {
+		var exec string
+		switch {
+		case len(hc.Test) == 0 || hc.Test[0] == "NONE":
+		case len(hc.Test) == 1:
+			exec = hc.Test[0]
+		case hc.Test[0] == "CMD-SHELL":
+			exec = strings.Join(hc.Test[1:], " ")
+		case hc.Test[0] == "CMD":
+			exec, ok = joinCommand(hc.Test[1:])
+			if !ok {
+				skip("healthcheck test (arguments with spaces)")
+			}
+		default:
+			skip("healthcheck test " + hc.Test[0])
+		}
+		if exec != "" {
+			srv.HealthCheck = &definitions.HealthCheck{
+				Exec:     exec,
+				Interval: hc.Interval,
+				Timeout:  hc.Timeout,
+				Retries:  hc.Retries,
+			}
+		}
+	}
+
+	return def, skipped, nil
+}
+
+// definitionsToCompose converts the service and chain definitions
+// (see DependencyGraph.Sorted) into a docker-compose file.
+func definitionsToCompose(defs []*definitions.ServiceDefinition) (*composeFile, []SkippedField) {
+	compose := &composeFile{
+		Version:  composeVersion,
+		Services: make(map[string]*composeService),
+	}
+
+	// Compose service names of the definitions (by type and name) and of
+	// the eris containers the definitions refer to in their links and
+	// volumes from. Services keep their names; chains get the "chain_"
+	// prefix if the name is already taken by a service.
+	var (
+		names      = make(map[string]string)
+		containers = make(map[string]string)
+	)
+	for _, def := range defs {
+		if def.Operations.ContainerType != definitions.TypeChain {
+			names[key(definitions.TypeService, def.Name)] = def.Name
+		}
+	}
+	for _, def := range defs {
+		if def.Operations.ContainerType == definitions.TypeChain {
+			name := def.Name
+			if _, ok := names[key(definitions.TypeService, name)]; ok {
+				name = "chain_" + name
+			}
+			names[key(definitions.TypeChain, def.Name)] = name
+		}
+	}
+	for _, def := range defs {
+		containers[def.Operations.SrvContainerName] = names[key(typeOf(def), def.Name)]
+	}
+
+	var skipped []SkippedField
+	for _, def := range defs {
+		name := names[key(typeOf(def), def.Name)]
+		skip := func(field string) {
+			skipped = append(skipped, SkippedField{name, field})
+		}
+
+		srv := def.Service
+		c := &composeService{
+			Image:       srv.Image,
+			Ports:       srv.Ports,
+			Expose:      srv.Expose,
+			Volumes:     srv.Volumes,
+			Environment: composeEnvironment(srv.Environment),
+			EnvFile:     composeList(srv.EnvFile),
+			NetworkMode: srv.Net,
+			PID:         srv.PID,
+			DNS:         composeList(srv.DNS),
+			DNSSearch:   composeList(srv.DNSSearch),
+			WorkingDir:  srv.WorkDir,
+			Hostname:    srv.HostName,
+			Domainname:  srv.DomainName,
+			User:        srv.User,
+			CPUShares:   srv.CPUShares,
+		}
+		if srv.Command != "" {
+			c.Command = composeCommand{srv.Command}
+		}
+		if srv.EntryPoint != "" {
+			c.Entrypoint = composeCommand{srv.EntryPoint}
+		}
+		if srv.MemLimit != 0 {
+			c.MemLimit = strconv.FormatInt(srv.MemLimit, 10)
+		}
+
+		switch restart := srv.Restart; {
+		case restart == "":
+		case restart == "always":
+			c.Restart = "always"
+		case strings.HasPrefix(restart, "max:"):
+			c.Restart = "on-failure:" + strings.TrimPrefix(restart, "max:")
+		default:
+			skip("restart " + restart)
+		}
+
+		var dependsOn []string
+		dependOn := func(service string) {
+			for _, s := range append(dependsOn, name) {
+				if s == service {
+					return
+				}
+			}
+			dependsOn = append(dependsOn, service)
+		}
+
+		for _, link := range srv.Links {
+			spl := strings.SplitN(link, ":", 2)
+			service, ok := containers[spl[0]]
+			if !ok {
+				c.ExternalLinks = append(c.ExternalLinks, link)
+				continue
+			}
+			if len(spl) > 1 {
+				service += ":" + spl[1]
+			}
+			c.Links = append(c.Links, service)
+			dependOn(strings.SplitN(service, ":", 2)[0])
+		}
+		for _, volumes := range srv.VolumesFrom {
+			spl := strings.SplitN(volumes, ":", 2)
+			service, ok := containers[spl[0]]
+			if !ok {
+				c.VolumesFrom = append(c.VolumesFrom, "container:"+volumes)
+				continue
+			}
+			if len(spl) > 1 {
+				service += ":" + spl[1]
+			}
+			c.VolumesFrom = append(c.VolumesFrom, service)
+			dependOn(strings.SplitN(service, ":", 2)[0])
+		}
+		if def.Dependencies != nil {
+			for _, dep := range def.Dependencies.Services {
+				if service, ok := names[key(definitions.TypeService, dep)]; ok {
+					dependOn(service)
+				}
+			}
+			for _, dep := range def.Dependencies.Chains {
+				if service, ok := names[key(definitions.TypeChain, dep)]; ok {
+					dependOn(service)
+				}
+			}
+		}
+
+		if srv.AutoData {
+			data := name + "_data"
+			compose.Services[data] = &composeService{
+				Image:      srv.Image,
+				Entrypoint: composeCommand{"true"},
+				User:       srv.User,
+			}
+			c.VolumesFrom = append(c.VolumesFrom, data)
+		}
+		c.DependsOn = composeList(dependsOn)
+
+		if hc := srv.HealthCheck; hc != nil {
+			if hc.Exec != "" {
+				c.HealthCheck = &composeHealthCheck{
+					Test:     composeCommand{"CMD-SHELL", hc.Exec},
+					Interval: hc.Interval,
+					Timeout:  hc.Timeout,
+					Retries:  hc.Retries,
+				}
+			} else {
+				skip("healthcheck port")
+			}
+		}
+		if len(srv.Endpoints) != 0 {
+			skip("endpoints")
+		}
+		if srv.ExecHost != "" {
+			skip("exec_host")
+		}
+
+		compose.Services[name] = c
+	}
+
+	sort.Sort(skippedFields(skipped))
+	return compose, skipped
+}
+
+// typeOf returns definitions.TypeChain for chain definitions
+// (see loaders.ChainsAsAService) and definitions.TypeService otherwise.
+func typeOf(def *definitions.ServiceDefinition) string {
+	if def.Operations.ContainerType == definitions.TypeChain {
+		return definitions.TypeChain
+	}
+	return definitions.TypeService
+}
+
+// joinCommand returns the command arguments as a string eris splits
+// back into the same arguments. It returns false if the arguments
+// contain spaces or the command relies on shell quoting and cannot
+// be split back.
+func joinCommand(args []string) (string, bool) {
+	if len(args) == 1 {
+		return args[0], !strings.ContainsAny(args[0], `"'\`)
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t\n") {
+			return strings.Join(args, " "), false
+		}
+	}
+	return strings.Join(args, " "), true
+}
+
+func reportSkipped(skipped []SkippedField, format string) {
+	for _, field := range skipped {
+		log.WithFields(log.Fields{
+			"=>":    field.Service,
+			"field": field.Field,
+		}).Warnf("No %s equivalent. Skipping", format)
+	}
+}
+
+type skippedFields []SkippedField
+
+func (s skippedFields) Len() int      { return len(s) }
+func (s skippedFields) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s skippedFields) Less(i, j int) bool {
+	if s[i].Service != s[j].Service {
+		return s[i].Service < s[j].Service
+	}
+	return s[i].Field < s[j].Field
+}
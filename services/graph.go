@@ -0,0 +1,337 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/eris-ltd/eris-logger"
+)
+
+// DependencyCycleError is returned by DependencyGraph.Sorted if
+// definitions depend on each other. Path lists the dependencies
+// which form the cycle, starting and ending with the same node.
+type DependencyCycleError struct {
+	Path []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("Dependency cycle detected: %s.\nPlease remove one of the dependencies from the definition files", strings.Join(e.Path, " -> "))
+}
+
+// DependencyGraph holds chain and service definitions connected by their
+// dependencies (the `chain` field, the `dependencies.services` and
+// `dependencies.chains` sections of definition files). Every chain or
+// service is loaded only once regardless of how many definitions
+// depend on it.
+type DependencyGraph struct {
+	chainFlag string
+	nodes     map[string]*dependencyNode
+	roots     []*dependencyNode
+	order     []*dependencyNode // in order of appearance
+
+	// Don't follow chain dependencies (used by BuildServicesGroup).
+	servicesOnly bool
+}
+
+type dependencyNode struct {
+	typ        string
+	name       string
+	definition *definitions.ServiceDefinition
+	deps       []*dependencyNode
+}
+
+func (n *dependencyNode) String() string {
+	return n.typ + " " + n.name
+}
+
+// NewDependencyGraph returns an empty dependency graph. If chainFlag
+// is not empty, it overwrites the `chain` field of every service
+// definition added to the graph, and the `${chain}` variables of the
+// definitions refer to it (the `$chain` and `${chain}` variables
+// otherwise resolve to the checked out chain).
+func NewDependencyGraph(chainFlag string) *DependencyGraph {
+	return &DependencyGraph{
+		chainFlag: chainFlag,
+		nodes:     make(map[string]*dependencyNode),
+	}
+}
+
+// BuildDependencyGraph is a shortcut for creating a dependency graph and
+// adding services by name to it. It returns definition loading errors.
+func BuildDependencyGraph(chainFlag string, servNames ...string) (*DependencyGraph, error) {
+	graph := NewDependencyGraph(chainFlag)
+	for _, name := range servNames {
+		if err := graph.AddService(name); err != nil {
+			return nil, err
+		}
+	}
+	return graph, nil
+}
+
+// AddService loads the service definition and its dependencies
+// recursively and adds them to the graph. AddService returns
+// definition loading errors.
+func (g *DependencyGraph) AddService(name string) error {
+	node, err := g.service(name)
+	if err != nil {
+		return err
+	}
+	g.addRoot(node)
+	return nil
+}
+
+// AddChain loads the chain definition (see loaders.ChainsAsAService)
+// and its dependencies recursively and adds them to the graph.
+// AddChain returns definition loading errors.
+func (g *DependencyGraph) AddChain(name string) error {
+	node, err := g.chain(name)
+	if err != nil {
+		return err
+	}
+	g.addRoot(node)
+	return nil
+}
+
+// Roots returns definitions explicitly added to the graph
+// by AddService or AddChain calls.
+func (g *DependencyGraph) Roots() []*definitions.ServiceDefinition {
+	var roots []*definitions.ServiceDefinition
+	for _, node := range g.roots {
+		roots = append(roots, node.definition)
+	}
+	return roots
+}
+
+// Services returns service (not chain) definitions from the graph
+// in order of appearance.
+func (g *DependencyGraph) Services() []*definitions.ServiceDefinition {
+	var services []*definitions.ServiceDefinition
+	for _, node := range g.order {
+		if node.typ == definitions.TypeService {
+			services = append(services, node.definition)
+		}
+	}
+	return services
+}
+
+// Sorted returns chain and service definitions ordered so that every
+// definition comes after all of its dependencies. Sorted returns
+// a *DependencyCycleError if there is a dependency cycle in the graph.
+func (g *DependencyGraph) Sorted() ([]*definitions.ServiceDefinition, error) {
+	nodes, err := g.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	var sorted []*definitions.ServiceDefinition
+	for _, node := range nodes {
+		sorted = append(sorted, node.definition)
+	}
+	return sorted, nil
+}
+
+// Levels groups chain and service definitions by their depth in the
+// graph: the first level holds definitions without dependencies, and
+// every next level holds definitions which only depend on definitions
+// from previous levels. Definitions within one level are independent
+// of each other and can be started at the same time (see StartGroup).
+// Levels returns a *DependencyCycleError if there is a dependency cycle
+// in the graph.
+func (g *DependencyGraph) Levels() ([][]*definitions.ServiceDefinition, error) {
+	nodes, err := g.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		levels [][]*definitions.ServiceDefinition
+		depth  = make(map[*dependencyNode]int)
+	)
+	for _, node := range nodes {
+		// Dependencies are already placed, because they come first.
+		for _, dep := range node.deps {
+			if depth[dep]+1 > depth[node] {
+				depth[node] = depth[dep] + 1
+			}
+		}
+
+		if depth[node] == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[depth[node]] = append(levels[depth[node]], node.definition)
+	}
+	return levels, nil
+}
+
+func (g *DependencyGraph) sort() ([]*dependencyNode, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		sorted []*dependencyNode
+		path   []*dependencyNode
+		state  = make(map[*dependencyNode]int)
+	)
+
+	var visit func(node *dependencyNode) error
+	visit = func(node *dependencyNode) error {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			// Trim the path to the start of the cycle.
+			var cycle []string
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == node {
+					for _, n := range path[i:] {
+						cycle = append(cycle, n.String())
+					}
+					break
+				}
+			}
+			return &DependencyCycleError{Path: append(cycle, node.String())}
+		}
+
+		state[node] = visiting
+		path = append(path, node)
+		for _, dep := range node.deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+
+		sorted = append(sorted, node)
+		return nil
+	}
+
+	for _, node := range g.order {
+		if err := visit(node); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+func (g *DependencyGraph) addRoot(node *dependencyNode) {
+	for _, root := range g.roots {
+		if root == node {
+			return
+		}
+	}
+	g.roots = append(g.roots, node)
+}
+
+func (g *DependencyGraph) service(name string) (*dependencyNode, error) {
+	if node, ok := g.nodes[key(definitions.TypeService, name)]; ok {
+		return node, nil
+	}
+
+	log.WithField("=>", name).Debug("Adding service to dependency graph")
+	srv, err := loaders.LoadServiceDefinitionOnChain(name, g.chainFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	node := g.add(definitions.TypeService, name, srv)
+
+	if srv.Chain != "" && !g.servicesOnly {
+		chn, err := ConnectChainToService(g.chainFlag, srv.Chain, srv)
+		if err != nil {
+			return nil, err
+		}
+		dep, err := g.addChain(chn)
+		if err != nil {
+			return nil, err
+		}
+		node.deps = append(node.deps, dep)
+	}
+
+	return node, g.addDependencies(node, srv.Dependencies)
+}
+
+func (g *DependencyGraph) chain(name string) (*dependencyNode, error) {
+	if node, ok := g.nodes[key(definitions.TypeChain, name)]; ok {
+		return node, nil
+	}
+
+	chn, err := loaders.ChainsAsAService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.addChain(chn)
+}
+
+func (g *DependencyGraph) addChain(chn *definitions.ServiceDefinition) (*dependencyNode, error) {
+	if node, ok := g.nodes[key(definitions.TypeChain, chn.Name)]; ok {
+		return node, nil
+	}
+
+	log.WithField("=>", chn.Name).Debug("Adding chain to dependency graph")
+	node := g.add(definitions.TypeChain, chn.Name, chn)
+
+	return node, g.addDependencies(node, chn.Dependencies)
+}
+
+func (g *DependencyGraph) add(typ, name string, definition *definitions.ServiceDefinition) *dependencyNode {
+	node := &dependencyNode{
+		typ:        typ,
+		name:       name,
+		definition: definition,
+	}
+	g.nodes[key(typ, name)] = node
+	g.order = append(g.order, node)
+	return node
+}
+
+func (g *DependencyGraph) addDependencies(node *dependencyNode, deps *definitions.Dependencies) error {
+	if deps == nil {
+		return nil
+	}
+
+	for _, dep := range deps.Services {
+		name, _, _, _ := util.ParseDependency(dep)
+		log.WithFields(log.Fields{
+			"=>":         node.name,
+			"dependency": name,
+		}).Debug("Found service dependency")
+		n, err := g.service(name)
+		if err != nil {
+			return err
+		}
+		node.deps = append(node.deps, n)
+	}
+
+	if g.servicesOnly {
+		return nil
+	}
+
+	for _, dep := range deps.Chains {
+		name, _, _, _ := util.ParseDependency(dep)
+		log.WithFields(log.Fields{
+			"=>":         node.name,
+			"dependency": name,
+		}).Debug("Found chain dependency")
+		n, err := g.chain(name)
+		if err != nil {
+			return err
+		}
+		node.deps = append(node.deps, n)
+	}
+
+	return nil
+}
+
+func key(typ, name string) string {
+	return typ + ":" + name
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+
+	. "github.com/eris-ltd/common/go/common"
+	log "github.com/eris-ltd/eris-logger"
+
+	"github.com/spf13/viper"
+)
+
+// layeredValue is a value of a service definition merged from its layers
+// (see loaders.DefinitionLayers) together with the files it came from.
+type layeredValue struct {
+	Key   string
+	Value interface{}
+	Files []string
+}
+
+// catServiceLayers displays every value of the do.Name service definition
+// merged from its layers with the files the value came from.
+func catServiceLayers(do *definitions.Do) error {
+	definition, err := config.LoadViperConfig(ServicesPath, do.Name)
+	if err != nil {
+		return err
+	}
+	layers, err := loaders.DefinitionLayers(ServicesPath, do.Name, definition)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	var files []string
+	for _, layer := range layers {
+		files = append(files, filepath.Base(layer.ConfigFileUsed()))
+	}
+	fmt.Fprintf(buf, "# Layers: %s\n\n", strings.Join(files, ", "))
+
+	tw := tabwriter.NewWriter(buf, 0, 1, 2, ' ', 0)
+	for _, value := range layeredValues(definition, layers) {
+		fmt.Fprintf(tw, "%s = %s\t# %s\n", value.Key, formatLayeredValue(value.Value), strings.Join(value.Files, ", "))
+	}
+	tw.Flush()
+
+	do.Result = buf.String()
+	log.Warn(buf.String())
+	return nil
+}
+
+// layeredValues merges the values of the definition layers the way the
+// loaders do and returns them sorted by key. Lists are appended (the
+// overlay lists replace them), other values are overwritten, and the
+// names of the layers other than the definition itself are not inherited.
+func layeredValues(definition *viper.Viper, layers []*viper.Viper) []layeredValue {
+	values := make(map[string]*layeredValue)
+	overlay := false
+	for _, layer := range layers {
+		file := filepath.Base(layer.ConfigFileUsed())
+		settings := make(map[string]interface{})
+		flattenSettings("", layer.AllSettings(), settings)
+		for key, value := range settings {
+			switch key {
+			case "extends":
+				continue
+			case "name", "service.name":
+				if layer != definition {
+					continue
+				}
+			}
+
+			existing, ok := values[key]
+			if !ok {
+				values[key] = &layeredValue{Key: key, Value: value, Files: []string{file}}
+				continue
+			}
+
+			// Zero values don't overwrite (see util.Merge).
+			switch value {
+			case "", false, int64(0), float64(0):
+				continue
+			}
+
+			list, isList := value.([]interface{})
+			existingList, wasList := existing.Value.([]interface{})
+			if isList && len(list) == 0 {
+				continue
+			}
+			if isList && wasList && !overlay {
+				existing.Value = append(append([]interface{}{}, existingList...), list...)
+				existing.Files = append(existing.Files, file)
+				continue
+			}
+			values[key] = &layeredValue{Key: key, Value: value, Files: []string{file}}
+		}
+		if layer == definition {
+			overlay = true
+		}
+	}
+
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []layeredValue
+	for _, key := range keys {
+		result = append(result, *values[key])
+	}
+	return result
+}
+
+// flattenSettings puts the nested settings values into the flat
+// map under the dot separated keys, e.g. "service.image".
+func flattenSettings(prefix string, settings map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range settings {
+		key = prefix + strings.ToLower(key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenSettings(key+".", v, flat)
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{})
+			for k, value := range v {
+				nested[fmt.Sprint(k)] = value
+			}
+			flattenSettings(key+".", nested, flat)
+		default:
+			flat[key] = value
+		}
+	}
+}
+
+func formatLayeredValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, formatLayeredValue(item))
+		}
+		return "[ " + strings.Join(items, ", ") + " ]"
+	}
+	return fmt.Sprint(value)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -19,18 +20,24 @@ import (
 	"github.com/eris-ltd/common/go/ipfs"
 )
 
+// ImportService fetches the service definition file from a location
+// (see loaders.Resolvers), validates it and writes it into ServicesPath.
+//
+//  do.Name - name of the service to import (required)
+//  do.Path - location of the definition file (required)
+//  do.Hash - IPFS hash of the definition file (if do.Path is not given)
+//
 func ImportService(do *definitions.Do) error {
-	fileName := filepath.Join(ServicesPath, do.Name)
-	if filepath.Ext(fileName) == "" {
-		fileName = fileName + ".toml"
+	location := do.Path
+	if location == "" {
+		location = "ipfs:" + do.Hash
 	}
 
-	if err := ipfs.GetFromIPFS(do.Hash, fileName, ""); err != nil {
+	if _, err := loaders.ImportDefinition("services", do.Name, location); err != nil {
 		return err
 	}
 
-	_, err := loaders.LoadServiceDefinition(do.Name)
-	if err != nil {
+	if _, err := loaders.LoadServiceDefinition(do.Name); err != nil {
 		return fmt.Errorf("error loading service:\n%v", err)
 	}
 
@@ -86,7 +93,9 @@ func RenameService(do *definitions.Do) error {
 	transformOnly := newNameBase == do.Name
 
 	if parseKnown(do.Name) {
-		serviceDef, err := loaders.LoadServiceDefinition(do.Name)
+		// The definition file is rewritten as is, without
+		// resolving its variables, extends, and overlays.
+		definition, err := config.LoadViperConfig(ServicesPath, do.Name)
 		if err != nil {
 			return err
 		}
@@ -96,7 +105,7 @@ func RenameService(do *definitions.Do) error {
 				"from": do.Name,
 				"to":   do.NewName,
 			}).Debug("Performing container rename")
-			err = perform.DockerRename(serviceDef.Operations, do.NewName)
+			err = perform.DockerRename(loaders.MockServiceDefinition(do.Name).Operations, do.NewName)
 			if err != nil {
 				return err
 			}
@@ -118,9 +127,7 @@ func RenameService(do *definitions.Do) error {
 			newFile = filepath.Join(ServicesPath, do.NewName)
 		}
 
-		serviceDef.Service.Name = strings.Replace(do.NewName, filepath.Ext(do.NewName), "", 1)
-		serviceDef.Name = serviceDef.Service.Name
-		err = WriteServiceDefinitionFile(serviceDef, newFile)
+		err = config.RenameDefinitionFile(definition, newNameBase, newFile)
 		if err != nil {
 			return err
 		}
@@ -163,8 +170,8 @@ func PortsService(do *definitions.Do) error {
 	}
 
 	if util.IsService(service.Service.Name, false) {
-		log.Debug("Service exists, getting port mapping")
-		return util.PrintPortMappings(service.Operations.SrvContainerName, do.Operations.Args)
+		log.Debug("Service exists, getting endpoints")
+		return util.PrintEndpoints(service.Service, service.Operations, do.Operations.Args)
 	}
 
 	return nil
@@ -246,10 +253,34 @@ func RmService(do *definitions.Do) error {
 	return nil
 }
 
+// CatService displays the service definition file.
+//
+//  do.Name      - name of the service (required)
+//  do.Resolved  - display the definition merged with the definitions
+//                 it extends and with its variables resolved (optional)
+//  do.ChainName - chain the ${chain} variables refer to with do.Resolved (optional)
+//  do.Layers    - display every value merged from the definition layers with
+//                 the files it came from (see loaders.DefinitionLayers) (optional)
+//
 func CatService(do *definitions.Do) error {
+	if do.Layers {
+		return catServiceLayers(do)
+	}
+	if do.Resolved {
+		srv, err := loaders.ResolveServiceDefinition(do.Name, do.ChainName)
+		if err != nil {
+			return err
+		}
+		buf := new(bytes.Buffer)
+		WriteDefaultServiceTOML(buf, srv)
+		do.Result = buf.String()
+		log.Warn(buf.String())
+		return nil
+	}
+
 	configs := util.GetGlobalLevelConfigFilesByType("services", true)
 	for _, c := range configs {
-		cName := strings.Split(filepath.Base(c), ".")[0]
+		cName := strings.TrimSuffix(filepath.Base(c), filepath.Ext(c))
 		if cName == do.Name {
 			cat, err := ioutil.ReadFile(c)
 			if err != nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/loaders"
@@ -13,59 +14,47 @@ import (
 )
 
 func StartService(do *definitions.Do) (err error) {
-	var services []*definitions.ServiceDefinition
-
 	do.Operations.Args = append(do.Operations.Args, do.ServicesSlice...)
 	log.WithField("args", do.Operations.Args).Info("Building services group")
-	for _, srv := range do.Operations.Args {
-		s, e := BuildServicesGroup(srv)
-		if e != nil {
-			return e
-		}
-		services = append(services, s...)
+	graph, err := BuildDependencyGraph(do.ChainName, do.Operations.Args...)
+	if err != nil {
+		return err
 	}
 
 	// [csk]: controls for ops reconciliation, overwrite will, e.g., merge the maps and stuff
-	for _, s := range services {
+	for _, s := range graph.Services() {
 		util.Merge(s.Operations, do.Operations)
 	}
 
-	log.Debug("Preparing to build chain")
-	for _, s := range services {
-		log.WithFields(log.Fields{
-			"name":         s.Name,
-			"dependencies": s.Dependencies,
-			"links":        s.Service.Links,
-			"volumes from": s.Service.VolumesFrom,
-		}).Debug()
-
-		// Spacer.
-		log.Debug()
+	// Services given on the command line get the command line
+	// environment and links.
+	for _, s := range graph.Roots() {
+		s.Service.Environment = append(s.Service.Environment, do.Env...)
+		s.Service.Links = append(s.Service.Links, do.Links...)
 	}
-	services, err = BuildChainGroup(do.ChainName, services)
+
+	levels, err := graph.Levels()
 	if err != nil {
 		return err
 	}
-	log.Debug("Checking services after build chain")
-	for _, s := range services {
-		log.WithFields(log.Fields{
-			"name":         s.Name,
-			"dependencies": s.Dependencies,
-			"links":        s.Service.Links,
-			"volumes from": s.Service.VolumesFrom,
-		}).Debug()
 
-		// Spacer.
-		log.Debug()
-	}
+	log.Debug("Checking services after dependency resolution")
+	for i, level := range levels {
+		for _, s := range level {
+			log.WithFields(log.Fields{
+				"name":         s.Name,
+				"level":        i,
+				"dependencies": s.Dependencies,
+				"links":        s.Service.Links,
+				"volumes from": s.Service.VolumesFrom,
+			}).Debug()
 
-	// NOTE: the top level service should be at the end of the list
-	topService := services[len(services)-1]
-	topService.Service.Environment = append(topService.Service.Environment, do.Env...)
-	topService.Service.Links = append(topService.Service.Links, do.Links...)
-	services[len(services)-1] = topService
+			// Spacer.
+			log.Debug()
+		}
+	}
 
-	return StartGroup(services)
+	return StartGroup(levels)
 }
 
 func KillService(do *definitions.Do) (err error) {
@@ -149,63 +138,133 @@ func ExecHandler(srvName string, args []string) (buf *bytes.Buffer, err error) {
 	return ExecService(do)
 }
 
-// TODO: test this recursion and service deps generally
+// BuildServicesGroup returns the srvName service definition along with
+// definitions of services it depends on (directly or indirectly). Every
+// service comes after its dependencies in the returned list. Chains the
+// services depend on are not included (see BuildDependencyGraph).
+// BuildServicesGroup returns definition loading or dependency cycle errors.
 func BuildServicesGroup(srvName string, services ...*definitions.ServiceDefinition) ([]*definitions.ServiceDefinition, error) {
 	log.WithFields(log.Fields{
 		"=>":        srvName,
 		"services#": len(services),
 	}).Debug("Building services group for")
-	srv, err := loaders.LoadServiceDefinition(srvName)
-	if err != nil {
+
+	graph := NewDependencyGraph("")
+	graph.servicesOnly = true
+	if err := graph.AddService(srvName); err != nil {
 		return nil, err
 	}
-	if srv.Dependencies != nil {
-		for _, sName := range srv.Dependencies.Services {
-			log.WithField("=>", sName).Debug("Found service dependency")
-			s, e := BuildServicesGroup(sName)
-			if e != nil {
-				return nil, e
-			}
-			services = append(services, s...)
-		}
+
+	sorted, err := graph.Sorted()
+	if err != nil {
+		return nil, err
 	}
-	services = append(services, srv)
-	return services, nil
+	return append(services, sorted...), nil
 }
 
-// start a group of chains or services. catch errors on a channel so we can stop as soon as something goes wrong
-func StartGroup(group []*definitions.ServiceDefinition) error {
-	log.WithField("services#", len(group)).Debug("Starting services group")
-	for _, srv := range group {
-		log.WithField("=>", srv.Name).Debug("Performing container start")
-		if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
-			return fmt.Errorf("Error starting service %s: %v", srv.Name, err)
+// MaxParallelStarts limits the number of containers StartGroup
+// starts at the same time.
+var MaxParallelStarts = 4
+
+// StartGroup starts chains and services level by level (see
+// DependencyGraph.Levels). Definitions within a level are started
+// concurrently, at most MaxParallelStarts at a time. Before the next
+// level is started, StartGroup waits for containers with a health check
+// to become healthy (see perform.DockerWaitHealthy). The first error
+// cancels starts which haven't begun yet, and containers which were
+// started by this StartGroup call are stopped before the error is returned.
+func StartGroup(levels [][]*definitions.ServiceDefinition) error {
+	var started []*definitions.ServiceDefinition
+
+	for i, level := range levels {
+		log.WithFields(log.Fields{
+			"level":     i,
+			"services#": len(level),
+		}).Debug("Starting services group level")
+
+		// Dependents of the last level aren't started here,
+		// so there's no need to wait for it to become healthy.
+		done, err := startLevel(level, i < len(levels)-1)
+		started = append(started, done...)
+		if err != nil {
+			stopGroup(started)
+			return err
 		}
 	}
 	return nil
 }
 
-// BuildChainGroup adds the chain specified in each service definition to the service group.
-// If chainName is not empty, it will overwrite chains specified in the defs.
-// Service defs which don't specify a chain or $chain won't connect to a chain.
-// NOTE: chains have to be started before services that depend on them.
-func BuildChainGroup(chainName string, services []*definitions.ServiceDefinition) (servicesAndChains []*definitions.ServiceDefinition, err error) {
-	var chains = make(map[string]*definitions.ServiceDefinition)
-	for _, srv := range services {
-		if srv.Chain != "" {
-			s, err := ConnectChainToService(chainName, srv.Chain, srv)
-			if err != nil {
-				return nil, err
+// startLevel starts independent chains or services concurrently
+// (and waits for them to become healthy if wait is true). It returns
+// those of them which weren't running before along with the first error.
+func startLevel(level []*definitions.ServiceDefinition, wait bool) ([]*definitions.ServiceDefinition, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		started  []*definitions.ServiceDefinition
+		firstErr error
+		slots    = make(chan struct{}, MaxParallelStarts)
+	)
+
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	for _, srv := range level {
+		slots <- struct{}{}
+		if failed() {
+			<-slots
+			log.WithField("=>", srv.Name).Debug("Canceling container start")
+			continue
+		}
+
+		wg.Add(1)
+		go func(srv *definitions.ServiceDefinition) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			running := perform.ContainerRunning(srv.Operations.SrvContainerName)
+
+			log.WithField("=>", srv.Name).Debug("Performing container start")
+			err := perform.DockerRunService(srv.Service, srv.Operations)
+
+			if err == nil && !running {
+				mu.Lock()
+				started = append(started, srv)
+				mu.Unlock()
 			}
-			if _, ok := chains[s.Name]; !ok {
-				chains[s.Name] = s
+			if err == nil && wait {
+				err = perform.DockerWaitHealthy(srv.Service, srv.Operations)
 			}
-		}
+
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("Error starting service %s: %v", srv.Name, err)
+				}
+				mu.Unlock()
+			}
+		}(srv)
 	}
-	for _, sd := range chains {
-		servicesAndChains = append(servicesAndChains, sd)
+	wg.Wait()
+
+	return started, firstErr
+}
+
+// stopGroup stops chains and services in reverse order, so that
+// dependent containers are stopped before their dependencies.
+func stopGroup(group []*definitions.ServiceDefinition) {
+	for i := len(group) - 1; i >= 0; i-- {
+		srv := group[i]
+		log.WithField("=>", srv.Name).Warn("Stopping container started before the error")
+		if err := perform.DockerStop(srv.Service, srv.Operations, 10); err != nil {
+			log.WithField("=>", srv.Name).Errorf("Error stopping container: %v", err)
+		}
 	}
-	return append(servicesAndChains, services...), nil
 }
 
 func ConnectChainToService(chainFlag, chainNameAndOpts string, srv *definitions.ServiceDefinition) (*definitions.ServiceDefinition, error) {
@@ -6,16 +6,21 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/eris-ltd/eris-cli/config"
 	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
 	"github.com/eris-ltd/eris-cli/tests"
 	"github.com/eris-ltd/eris-cli/util"
 	ver "github.com/eris-ltd/eris-cli/version"
 
+	"github.com/eris-ltd/common/go/common"
 	log "github.com/eris-ltd/eris-logger"
+	"gopkg.in/yaml.v2"
 )
 
 const servName = "ipfs"
@@ -430,6 +435,307 @@ func TestStartKillServiceWithDependencies(t *testing.T) {
 
 }
 
+func TestDependencyGraphSorted(t *testing.T) {
+	for name, deps := range map[string]string{
+		"graph_a": `"graph_b", "graph_c"`,
+		"graph_b": `"graph_c"`,
+		"graph_c": ``,
+	} {
+		if err := tests.FakeServiceDefinition(name, `
+name = "`+name+`"
+
+[service]
+image = "`+path.Join(ver.ERIS_REG_DEF, ver.ERIS_IMG_IPFS)+`"
+
+[dependencies]
+services = [ `+deps+` ]
+`); err != nil {
+			t.Fatalf("can't create a fake service definition: %v", err)
+		}
+	}
+
+	graph, err := BuildDependencyGraph("", "graph_a", "graph_c")
+	if err != nil {
+		t.Fatalf("expected graph to build, got %v", err)
+	}
+
+	if roots := graph.Roots(); len(roots) != 2 {
+		t.Fatalf("expected 2 roots, got %d", len(roots))
+	}
+
+	sorted, err := graph.Sorted()
+	if err != nil {
+		t.Fatalf("expected graph to sort, got %v", err)
+	}
+
+	var names []string
+	for _, srv := range sorted {
+		names = append(names, srv.Name)
+	}
+	if strings.Join(names, " ") != "graph_c graph_b graph_a" {
+		t.Fatalf("expected dependencies to come first once, got %v", names)
+	}
+
+	levels, err := graph.Levels()
+	if err != nil {
+		t.Fatalf("expected graph to split into levels, got %v", err)
+	}
+	if len(levels) != 3 {
+		t.Fatalf("expected 3 levels, got %d", len(levels))
+	}
+	for i, name := range []string{"graph_c", "graph_b", "graph_a"} {
+		if len(levels[i]) != 1 || levels[i][0].Name != name {
+			t.Fatalf("expected level %d to contain %s only, got %v", i, name, levels[i])
+		}
+	}
+}
+
+func TestStartGroupStopsOnError(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	if err := tests.FakeServiceDefinition("broken", `
+name = "broken"
+
+[service]
+image = "`+path.Join(ver.ERIS_REG_DEF, ver.ERIS_IMG_IPFS)+`"
+restart = "max:x"
+
+[dependencies]
+services = [ "keys" ]
+`); err != nil {
+		t.Fatalf("can't create a fake service definition: %v", err)
+	}
+
+	do := def.NowDo()
+	do.Operations.Args = []string{"broken"}
+	if err := StartService(do); err == nil {
+		t.Fatalf("expected service start to fail")
+	}
+
+	if util.Running(def.TypeService, "keys") {
+		t.Fatalf("expecting keys service started in the same run to be stopped")
+	}
+	if util.Exists(def.TypeService, "broken") {
+		t.Fatalf("expecting broken service container not to exist")
+	}
+}
+
+func TestDependencyGraphCycle(t *testing.T) {
+	for name, dep := range map[string]string{
+		"cycle_a": "cycle_b",
+		"cycle_b": "cycle_c",
+		"cycle_c": "cycle_a",
+	} {
+		if err := tests.FakeServiceDefinition(name, `
+name = "`+name+`"
+
+[service]
+image = "`+path.Join(ver.ERIS_REG_DEF, ver.ERIS_IMG_IPFS)+`"
+
+[dependencies]
+services = [ "`+dep+`" ]
+`); err != nil {
+			t.Fatalf("can't create a fake service definition: %v", err)
+		}
+	}
+
+	graph, err := BuildDependencyGraph("", "cycle_a")
+	if err != nil {
+		t.Fatalf("expected graph to build, got %v", err)
+	}
+
+	_, err = graph.Sorted()
+	cycle, ok := err.(*DependencyCycleError)
+	if !ok {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+	if path := strings.Join(cycle.Path, " -> "); path != "service cycle_a -> service cycle_b -> service cycle_c -> service cycle_a" {
+		t.Fatalf("expected cycle path to be reported, got %q", path)
+	}
+
+	do := def.NowDo()
+	do.Operations.Args = []string{"cycle_b"}
+	if err := StartService(do); err == nil {
+		t.Fatalf("expected start to fail on a dependency cycle")
+	}
+}
+
+func TestComposeToDefinitions(t *testing.T) {
+	defs, skipped, err := composeToDefinitions([]byte(`
+version: "2"
+services:
+  web:
+    image: nginx
+    command: ["nginx", "-g", "daemon off;"]
+    restart: on-failure:3
+    links:
+      - db:database
+      - external
+    depends_on:
+      - cache
+    volumes_from:
+      - db
+    ports:
+      - 8080:80
+    environment:
+      MODE: production
+    env_file: web.env
+    mem_limit: 64m
+    build: .
+  db:
+    image: postgres
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+      interval: 2s
+  cache:
+    image: redis
+networks:
+  default:
+`), "/compose")
+	if err != nil {
+		t.Fatalf("expected compose file to convert, got %v", err)
+	}
+
+	var names []string
+	for _, def := range defs {
+		names = append(names, def.Name)
+	}
+	if strings.Join(names, " ") != "cache db web" {
+		t.Fatalf("expected cache, db, and web services, got %v", names)
+	}
+
+	web := defs[2]
+	if deps := strings.Join(web.Dependencies.Services, " "); deps != "cache:cache:l db:database" {
+		t.Fatalf("expected cache and db dependencies, got %q", deps)
+	}
+	if strings.Join(web.Service.Links, " ") != "external" {
+		t.Fatalf("expected external link, got %v", web.Service.Links)
+	}
+	if web.Service.Command != "nginx -g daemon off;" {
+		t.Fatalf("expected command, got %q", web.Service.Command)
+	}
+	if web.Service.Restart != "max:3" {
+		t.Fatalf("expected max:3 restart policy, got %q", web.Service.Restart)
+	}
+	if strings.Join(web.Service.Environment, " ") != "MODE=production" {
+		t.Fatalf("expected environment, got %v", web.Service.Environment)
+	}
+	if strings.Join(web.Service.EnvFile, " ") != filepath.Join("/compose", "web.env") {
+		t.Fatalf("expected env file resolved, got %v", web.Service.EnvFile)
+	}
+	if web.Service.MemLimit != 64*1024*1024 {
+		t.Fatalf("expected memory limit, got %d", web.Service.MemLimit)
+	}
+
+	db := defs[1]
+	if db.Service.HealthCheck == nil || db.Service.HealthCheck.Exec != "pg_isready" || db.Service.HealthCheck.Interval != "2s" {
+		t.Fatalf("expected health check, got %v", db.Service.HealthCheck)
+	}
+
+	var fields []string
+	for _, field := range skipped {
+		fields = append(fields, field.String())
+	}
+	if strings.Join(fields, ", ") != "(top level): networks, web: build, web: command (arguments with spaces)" {
+		t.Fatalf("expected skipped fields reported, got %v", fields)
+	}
+}
+
+func TestComposeToDefinitionsVersion1(t *testing.T) {
+	defs, _, err := composeToDefinitions([]byte(`
+app:
+  image: app
+  links:
+    - db
+db:
+  image: postgres
+`), "")
+	if err != nil {
+		t.Fatalf("expected compose file to convert, got %v", err)
+	}
+	if len(defs) != 2 || defs[0].Name != "app" || strings.Join(defs[0].Dependencies.Services, " ") != "db:db:l" {
+		t.Fatalf("expected app to depend on db, got %v", defs)
+	}
+
+	if _, _, err := composeToDefinitions([]byte(`
+app:
+  build: .
+`), ""); err == nil {
+		t.Fatalf("expected services without image to fail")
+	}
+}
+
+func TestDefinitionsToCompose(t *testing.T) {
+	chain := def.BlankServiceDefinition()
+	chain.Name = "simplechain"
+	chain.Service.Image = "erisdb"
+	chain.Service.AutoData = true
+	chain.Operations.ContainerType = def.TypeChain
+	chain.Operations.SrvContainerName = "eris_chain_simplechain"
+	chain.Dependencies = &def.Dependencies{Services: []string{"keys"}}
+
+	keys := def.BlankServiceDefinition()
+	keys.Name = "keys"
+	keys.Service.Image = "keys"
+	keys.Service.AutoData = true
+	keys.Operations.ContainerType = def.TypeService
+	keys.Operations.SrvContainerName = "eris_service_keys"
+
+	app := def.BlankServiceDefinition()
+	app.Name = "app"
+	app.Service.Image = "app"
+	app.Service.Restart = "always"
+	app.Service.Endpoints = map[string]string{"api": "8080"}
+	app.Service.Links = []string{"eris_chain_simplechain:chain", "other:other"}
+	app.Service.VolumesFrom = []string{"eris_chain_simplechain:rw"}
+	app.Service.HealthCheck = &def.HealthCheck{Exec: "curl localhost:8080"}
+	app.Operations.ContainerType = def.TypeService
+	app.Operations.SrvContainerName = "eris_service_app"
+
+	compose, skipped := definitionsToCompose([]*def.ServiceDefinition{keys, chain, app})
+
+	var names []string
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, " ") != "app keys keys_data simplechain simplechain_data" {
+		t.Fatalf("expected services with data containers, got %v", names)
+	}
+
+	c := compose.Services["app"]
+	if strings.Join(c.Links, " ") != "simplechain:chain" || strings.Join(c.ExternalLinks, " ") != "other:other" {
+		t.Fatalf("expected chain link and external link, got %v and %v", c.Links, c.ExternalLinks)
+	}
+	if strings.Join(c.VolumesFrom, " ") != "simplechain:rw" || strings.Join(c.DependsOn, " ") != "simplechain" {
+		t.Fatalf("expected chain volumes and dependency, got %v and %v", c.VolumesFrom, c.DependsOn)
+	}
+	if c.Restart != "always" || c.HealthCheck == nil || strings.Join(c.HealthCheck.Test, " ") != "CMD-SHELL curl localhost:8080" {
+		t.Fatalf("expected restart policy and health check, got %q and %v", c.Restart, c.HealthCheck)
+	}
+
+	c = compose.Services["simplechain"]
+	if strings.Join(c.VolumesFrom, " ") != "simplechain_data" || strings.Join(c.DependsOn, " ") != "keys" {
+		t.Fatalf("expected chain data container and keys dependency, got %v and %v", c.VolumesFrom, c.DependsOn)
+	}
+	if strings.Join(compose.Services["simplechain_data"].Entrypoint, " ") != "true" {
+		t.Fatalf("expected data container to exit, got %v", compose.Services["simplechain_data"].Entrypoint)
+	}
+
+	if len(skipped) != 1 || skipped[0].String() != "app: endpoints" {
+		t.Fatalf("expected endpoints skipped, got %v", skipped)
+	}
+
+	out, err := yaml.Marshal(compose)
+	if err != nil {
+		t.Fatalf("expected compose file to marshal, got %v", err)
+	}
+	defs, _, err := composeToDefinitions(out, "")
+	if err != nil || len(defs) != 5 {
+		t.Fatalf("expected exported compose file to import back, got %v (%v)", defs, err)
+	}
+}
+
 func start(t *testing.T, serviceName string, publishAll bool) {
 	do := def.NowDo()
 	do.Operations.Args = []string{serviceName}
@@ -452,3 +758,62 @@ func kill(t *testing.T, serviceName string, wipe bool) {
 		t.Fatalf("expected service to be stopped, got %v", err)
 	}
 }
+
+func TestLayeredValues(t *testing.T) {
+	for name, definition := range map[string]string{
+		"layers_base": `
+name = "layers_base"
+
+[service]
+name = "layers_base"
+image = "base image"
+ports = [ "1234" ]
+user = "base"
+`,
+		"layers": `
+extends = "layers_base"
+name = "layers"
+
+[service]
+ports = [ "5678" ]
+volumes = [ "/local" ]
+user = ""
+`,
+		"layers.staging": `
+[service]
+image = "staging image"
+volumes = [ "/staging" ]
+`,
+	} {
+		if err := tests.FakeDefinitionFile(common.ServicesPath, name, definition); err != nil {
+			t.Fatalf("cannot place a definition file")
+		}
+	}
+
+	loaders.Environment = "staging"
+	defer func() { loaders.Environment = "" }()
+
+	definition, err := config.LoadViperConfig(common.ServicesPath, "layers")
+	if err != nil {
+		t.Fatalf("expected definition to load, got %v", err)
+	}
+	layers, err := loaders.DefinitionLayers(common.ServicesPath, "layers", definition)
+	if err != nil {
+		t.Fatalf("expected definition layers, got %v", err)
+	}
+
+	var values []string
+	for _, value := range layeredValues(definition, layers) {
+		values = append(values, value.Key+" = "+formatLayeredValue(value.Value)+" # "+strings.Join(value.Files, ", "))
+	}
+	expected := []string{
+		`name = "layers" # layers.toml`,
+		`service.image = "staging image" # layers.staging.toml`,
+		`service.ports = [ "1234", "5678" ] # layers_base.toml, layers.toml`,
+		`service.user = "base" # layers_base.toml`,
+		`service.volumes = [ "/staging" ] # layers.staging.toml`,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(values, "\n"))
+	}
+}
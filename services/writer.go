@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -50,7 +51,7 @@ func WriteServiceDefinitionFile(serviceDef *def.ServiceDefinition, fileName stri
 	return nil
 }
 
-func WriteDefaultServiceTOML(writer *os.File, serviceDef *def.ServiceDefinition) {
+func WriteDefaultServiceTOML(writer io.Writer, serviceDef *def.ServiceDefinition) {
 
 	writer.Write([]byte("# This is a TOML config file.\n# For more information, see https://github.com/toml-lang/toml\n\n"))
 	enc := toml.NewEncoder(writer)
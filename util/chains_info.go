@@ -3,7 +3,9 @@ package util
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/eris-ltd/common/go/common"
 	log "github.com/eris-ltd/eris-logger"
@@ -30,23 +32,115 @@ func NullHead() error {
 	return ChangeHead("")
 }
 
-// Get the current active chain (top of the HEAD file)
+// LocalHeadFile is the name of the file in the working directory which
+// overrides the checked out chain for the project.
+const LocalHeadFile = ".eris-chain"
+
+// HeadEntry is a record of the HEAD file.
+type HeadEntry struct {
+	Name string
+	// Time of the checkout (zero for records without a timestamp)
+	Time time.Time
+}
+
+// Get the current active chain: the project-local chain from
+// the LocalHeadFile file or the top of the HEAD file otherwise.
 // Returns chain name
 func GetHead() (string, error) {
-	// TODO: only read the one line!
-	f, err := ioutil.ReadFile(common.HEAD)
-	if err != nil {
+	if head, err := GetLocalHead(); err != nil {
 		return "", err
+	} else if head != "" {
+		return head, nil
 	}
 
-	fspl := strings.Split(string(f), "\n")
-	head := fspl[0]
+	return GetGlobalHead()
+}
+
+// Get the chain checked out with [eris chains checkout]
+// (top of the HEAD file), ignoring the project-local one.
+func GetGlobalHead() (string, error) {
+	history, err := HeadHistory()
+	if err != nil {
+		return "", err
+	}
 
-	if head == "" {
+	if len(history) == 0 || history[0].Name == "" {
 		return "", fmt.Errorf("There is no chain checked out")
 	}
 
-	return head, nil
+	return history[0].Name, nil
+}
+
+// Get the project-local chain from the LocalHeadFile file in
+// the working directory. Returns an empty string if there's none.
+func GetLocalHead() (string, error) {
+	b, err := ioutil.ReadFile(LocalHeadFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(strings.SplitN(string(b), "\n", 2)[0]), nil
+}
+
+// Write the project-local chain into the LocalHeadFile
+// file or remove the file if name is empty.
+func ChangeLocalHead(name string) error {
+	if name == "" {
+		if err := os.Remove(LocalHeadFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return ioutil.WriteFile(LocalHeadFile, []byte(name+"\n"), 0666)
+}
+
+// Read the HEAD file records, the latest checkout first. Blank
+// names stand for the times there was no chain checked out. The
+// checkout times are read from the HEAD_HISTORY file next to HEAD.
+func HeadHistory() ([]HeadEntry, error) {
+	names, err := readHeadLines(common.HEAD)
+	if err != nil {
+		return nil, err
+	}
+	// Older binaries don't write the times, so
+	// the file may be missing or out of step.
+	times, _ := readHeadLines(headHistoryFile())
+
+	history := make([]HeadEntry, len(names))
+	for i, line := range names {
+		history[i].Name = strings.SplitN(line, "\t", 2)[0]
+		if i >= len(times) {
+			continue
+		}
+		fields := strings.SplitN(times[i], "\t", 2)
+		if len(fields) > 1 && fields[0] == history[i].Name {
+			history[i].Time, _ = time.Parse(time.RFC3339, fields[1])
+		}
+	}
+	return history, nil
+}
+
+// Get the chain checked out before the current one (from the HEAD file).
+func PreviousHead() (string, error) {
+	history, err := HeadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	var current string
+	if len(history) > 0 {
+		current = history[0].Name
+	}
+
+	for _, entry := range history {
+		if entry.Name != "" && entry.Name != current && IsKnownChain(entry.Name) {
+			return entry.Name, nil
+		}
+	}
+	return "", fmt.Errorf("There is no previously checked out chain")
 }
 
 // Add a new entry (name) to the top of the HEAD file
@@ -58,29 +152,56 @@ func ChangeHead(name string) error {
 	}
 
 	log.Debug("Chain name known (or blank). Saving to head file")
-	// read in the entire head file and clip
-	// if we have reached the max length
-	b, err := ioutil.ReadFile(common.HEAD)
+	if _, err := os.Stat(common.HEAD); err != nil {
+		return err
+	}
+
+	// The HEAD file keeps the bare names (a blank name for no head),
+	// so that scripts and older binaries can read it.
+	if err := prependHeadLine(common.HEAD, name); err != nil {
+		return err
+	}
+	if err := prependHeadLine(headHistoryFile(), name+"\t"+time.Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	log.Debug("Head file saved")
+	return nil
+}
+
+// headHistoryFile is the file with the HEAD file
+// records along with their checkout times.
+func headHistoryFile() string {
+	return common.HEAD + "_HISTORY"
+}
+
+func readHeadLines(fileName string) ([]string, error) {
+	b, err := ioutil.ReadFile(fileName)
 	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+// prependHeadLine adds the line to the top of the
+// file and clips the file if it has reached MaxHead lines.
+func prependHeadLine(fileName, line string) error {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
-	bspl := strings.Split(string(b), "\n")
+	bspl := strings.SplitAfter(string(b), "\n")
 	var bsp string
 	if len(bspl) >= MaxHead {
-		bsp = strings.Join(bspl[:MaxHead-1], "\n")
+		bsp = strings.Join(bspl[:MaxHead-1], "")
 	} else {
 		bsp = string(b)
 	}
 
-	// add the new head
-	var s string
-	// handle empty head
-	s = name + "\n" + bsp
-	err = ioutil.WriteFile(common.HEAD, []byte(s), 0666)
-	if err != nil {
-		return err
-	}
-
-	log.Debug("Head file saved")
-	return nil
+	return ioutil.WriteFile(fileName, []byte(line+"\n"+bsp), 0666)
 }
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+func testHeadSetup(t *testing.T, chains ...string) func() {
+	dir, err := ioutil.TempDir("", "eris_head_")
+	if err != nil {
+		t.Fatalf("can't create a temporary directory: %v", err)
+	}
+
+	oldHead, oldChainsPath := common.HEAD, common.ChainsPath
+	common.HEAD, common.ChainsPath = filepath.Join(dir, "HEAD"), dir
+
+	for _, chain := range chains {
+		if err := ioutil.WriteFile(filepath.Join(dir, chain+".toml"), []byte(`name = "`+chain+`"`), 0644); err != nil {
+			t.Fatalf("can't write a chain definition: %v", err)
+		}
+	}
+
+	return func() {
+		common.HEAD, common.ChainsPath = oldHead, oldChainsPath
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHeadHistory(t *testing.T) {
+	defer testHeadSetup(t, "a", "b", "old")()
+
+	// A record without a timestamp.
+	if err := ioutil.WriteFile(common.HEAD, []byte("old\n"), 0666); err != nil {
+		t.Fatalf("can't write the HEAD file: %v", err)
+	}
+
+	for _, name := range []string{"a", "unknown", "b"} {
+		if err := ChangeHead(name); err != nil {
+			t.Fatalf("expected head to change to %q, got %v", name, err)
+		}
+	}
+
+	history, err := HeadHistory()
+	if err != nil {
+		t.Fatalf("expected to read the history, got %v", err)
+	}
+	if len(history) != 3 || history[0].Name != "b" || history[1].Name != "a" || history[2].Name != "old" {
+		t.Fatalf("expected history [b a old], got %v", history)
+	}
+	if history[0].Time.IsZero() || !history[2].Time.IsZero() {
+		t.Fatalf("expected only new records to have timestamps, got %v", history)
+	}
+	if head, _ := ioutil.ReadFile(common.HEAD); string(head) != "b\na\nold\n" {
+		t.Fatalf("expected the HEAD file to list bare names, got %q", head)
+	}
+
+	if head, err := GetHead(); err != nil || head != "b" {
+		t.Fatalf("expected head b, got %q (%v)", head, err)
+	}
+	if previous, err := PreviousHead(); err != nil || previous != "a" {
+		t.Fatalf("expected previous head a, got %q (%v)", previous, err)
+	}
+
+	ChangeHead("a")
+	if previous, err := PreviousHead(); err != nil || previous != "b" {
+		t.Fatalf("expected previous head b after switching back, got %q (%v)", previous, err)
+	}
+
+	NullHead()
+	if head, err := GetHead(); err == nil {
+		t.Fatalf("expected no head, got %q", head)
+	}
+	if previous, err := PreviousHead(); err != nil || previous != "a" {
+		t.Fatalf("expected previous head a after uncheckout, got %q (%v)", previous, err)
+	}
+}
+
+func TestHeadHistoryClipped(t *testing.T) {
+	defer testHeadSetup(t, "a")()
+
+	defer func(max int) { MaxHead = max }(MaxHead)
+	MaxHead = 3
+
+	ioutil.WriteFile(common.HEAD, []byte{}, 0666)
+	for i := 0; i < 5; i++ {
+		ChangeHead("a")
+	}
+
+	if history, _ := HeadHistory(); len(history) != MaxHead {
+		t.Fatalf("expected %d records, got %v", MaxHead, history)
+	}
+}
+
+func TestLocalHead(t *testing.T) {
+	defer testHeadSetup(t, "global", "local")()
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(common.ChainsPath)
+
+	ioutil.WriteFile(common.HEAD, []byte{}, 0666)
+	ChangeHead("global")
+
+	if err := ChangeLocalHead("local"); err != nil {
+		t.Fatalf("expected local head to be written, got %v", err)
+	}
+	if head, err := GetHead(); err != nil || head != "local" {
+		t.Fatalf("expected local head to override, got %q (%v)", head, err)
+	}
+	if head, err := GetGlobalHead(); err != nil || head != "global" {
+		t.Fatalf("expected global head, got %q (%v)", head, err)
+	}
+
+	if err := ChangeLocalHead(""); err != nil {
+		t.Fatalf("expected local head to be removed, got %v", err)
+	}
+	if head, err := GetHead(); err != nil || head != "global" {
+		t.Fatalf("expected global head without a local checkout, got %q (%v)", head, err)
+	}
+}
@@ -0,0 +1,200 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Endpoint is a named network endpoint of a chain or a service container.
+type Endpoint struct {
+	// endpoint name, e.g. "rpc" (see definitions.EndpointRPC and others),
+	// or the port number if the port isn't named
+	Name string `json:"name"`
+	// container port, e.g. "46657/tcp"
+	Port string `json:"port"`
+	// host port the definition file and the --ports flag publish the
+	// container port to (empty if the port isn't published or random)
+	Published string `json:"published,omitempty"`
+	// host:port address the endpoint of the running container is
+	// reachable at (empty if the container isn't running)
+	Address string `json:"address,omitempty"`
+}
+
+// SplitPorts splits the --ports flag value into port assignments
+// (see MapPorts).
+func SplitPorts(ports string) []string {
+	return strings.FieldsFunc(ports, func(c rune) bool {
+		return unicode.IsSpace(c) || c == ','
+	})
+}
+
+// Endpoints returns the endpoints of the chain or the service: the ports
+// and exposed ports from the srv definition named according to the srv
+// endpoints table or definitions.WellKnownEndpoints. Published ports
+// take the ops.Ports reassignments into account, or the reassignments
+// the ops.SrvContainerName container was created with (e.g. picked by
+// --auto-ports). If the container is running, the actual addresses are
+// filled in from the Docker port bindings.
+func Endpoints(srv *def.Service, ops *def.Operation) []*Endpoint {
+	if DockerClient == nil || ops == nil || ops.SrvContainerName == "" {
+		return definedEndpoints(srv, ops)
+	}
+	container, err := DockerClient.InspectContainer(ops.SrvContainerName)
+	if err != nil {
+		return definedEndpoints(srv, ops)
+	}
+
+	if container.Config != nil && container.Config.Labels[def.LabelPorts] != "" {
+		recorded := *ops
+		recorded.Ports = container.Config.Labels[def.LabelPorts]
+		ops = &recorded
+	}
+
+	endpoints := definedEndpoints(srv, ops)
+	if !container.State.Running {
+		return endpoints
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Address, _ = ContainerAddress(container, endpoint.Port)
+	}
+	return endpoints
+}
+
+// EndpointPort returns the container port number of the named endpoint
+// of the chain or the service defined by srv, e.g. "46657" for "rpc".
+func EndpointPort(srv *def.Service, name string) (string, error) {
+	for _, endpoint := range definedEndpoints(srv, nil) {
+		if endpoint.Name == name {
+			return strings.Split(endpoint.Port, "/")[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s has no %q endpoint. Please add it to the [service.endpoints] table of the definition file", srv.Name, name)
+}
+
+// EndpointAddress returns the host:port address the named endpoint
+// of the running container defined by srv can be reached at.
+func EndpointAddress(container *docker.Container, srv *def.Service, name string) (string, error) {
+	port, err := EndpointPort(srv, name)
+	if err != nil {
+		return "", err
+	}
+	return ContainerAddress(container, port)
+}
+
+// LinkedEndpoint returns the host:port address the named endpoint of
+// the chain or the service defined by srv is reachable at from a container
+// it's linked to under the alias name, e.g. "chain:46657".
+func LinkedEndpoint(alias string, srv *def.Service, name string) (string, error) {
+	port, err := EndpointPort(srv, name)
+	if err != nil {
+		return "", err
+	}
+	return alias + ":" + port, nil
+}
+
+// PrintEndpoints displays the endpoints of the chain or the service
+// (see Endpoints) as a table. If names are given, only the addresses of
+// those endpoints are displayed, one per line. Names which are port numbers
+// rather than endpoint names are looked up in the container port bindings
+// as before (see PrintPortMappings).
+func PrintEndpoints(srv *def.Service, ops *def.Operation, names []string) error {
+	endpoints := Endpoints(srv, ops)
+
+	if len(names) == 0 {
+		tw := tabwriter.NewWriter(config.GlobalConfig.Writer, 6, 1, 5, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tCONTAINER PORT\tPUBLISHED\tADDRESS")
+		for _, endpoint := range endpoints {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", endpoint.Name, endpoint.Port, endpoint.Published, endpoint.Address)
+		}
+		return tw.Flush()
+	}
+
+	byName := make(map[string]*Endpoint)
+	for _, endpoint := range endpoints {
+		byName[endpoint.Name] = endpoint
+	}
+
+	var addresses []string
+	for _, name := range names {
+		endpoint, ok := byName[name]
+		if !ok {
+			return PrintPortMappings(ops.SrvContainerName, names)
+		}
+		if endpoint.Address == "" {
+			return fmt.Errorf("The %q endpoint of %s is not published or the container is not running", name, srv.Name)
+		}
+		addresses = append(addresses, endpoint.Address)
+	}
+	for _, address := range addresses {
+		fmt.Fprintln(config.GlobalConfig.Writer, address)
+	}
+	return nil
+}
+
+func definedEndpoints(srv *def.Service, ops *def.Operation) []*Endpoint {
+	names := make(map[string]string)
+	for name, port := range srv.Endpoints {
+		names[PortAndProtocol(port)] = name
+	}
+
+	var published map[string]string
+	if ops == nil || !ops.PublishAllPorts {
+		var assignments []string
+		if ops != nil {
+			assignments = SplitPorts(ops.Ports)
+		}
+		published = MapPorts(srv.Ports, assignments)
+	}
+
+	var (
+		endpoints []*Endpoint
+		seen      = make(map[string]bool)
+	)
+	add := func(port, host string) {
+		if port == "" || seen[port] {
+			return
+		}
+		seen[port] = true
+
+		number := strings.Split(port, "/")[0]
+		name := names[port]
+		if name == "" {
+			name = def.WellKnownEndpoints[number]
+		}
+		if name == "" {
+			name = number
+		}
+		endpoints = append(endpoints, &Endpoint{Name: name, Port: port, Published: host})
+	}
+
+	for _, entry := range srv.Ports {
+		_, _, exposed := PortComponents(entry)
+		add(exposed, published[exposed])
+	}
+	for _, entry := range srv.Expose {
+		add(PortAndProtocol(entry), "")
+	}
+
+	// Named ports which are neither published nor exposed
+	// are still reachable from the linked containers.
+	var rest []string
+	for port := range names {
+		if !seen[port] {
+			rest = append(rest, port)
+		}
+	}
+	sort.Strings(rest)
+	for _, port := range rest {
+		add(port, "")
+	}
+
+	return endpoints
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+)
+
+func TestSplitPorts(t *testing.T) {
+	actual := SplitPorts(" 17000,18000-  50000:5001\t")
+	if expected := []string{"17000", "18000-", "50000:5001"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	srv := &def.Service{
+		Name:      "test",
+		Ports:     []string{"46656:46656", "46657:46657", "1337:1337"},
+		Expose:    []string{"9000"},
+		Endpoints: map[string]string{"metrics": "9000", "debug": "6060"},
+	}
+	ops := &def.Operation{Ports: "50000,50001"}
+
+	expected := []*Endpoint{
+		{Name: "p2p", Port: "46656/tcp", Published: "50000"},
+		{Name: "rpc", Port: "46657/tcp", Published: "50001"},
+		{Name: "api", Port: "1337/tcp", Published: "1337"},
+		{Name: "metrics", Port: "9000/tcp"},
+		{Name: "debug", Port: "6060/tcp"},
+	}
+	if actual := Endpoints(srv, ops); !reflect.DeepEqual(actual, expected) {
+		for _, endpoint := range actual {
+			t.Logf("%+v", endpoint)
+		}
+		t.Fatalf("unexpected endpoints")
+	}
+
+	ops.PublishAllPorts = true
+	for _, endpoint := range Endpoints(srv, ops) {
+		if endpoint.Published != "" {
+			t.Fatalf("expected random published ports, got %+v", endpoint)
+		}
+	}
+}
+
+func TestEndpointPort(t *testing.T) {
+	srv := &def.Service{
+		Name:      "test",
+		Ports:     []string{"46657:46657", "8080:80"},
+		Endpoints: map[string]string{"api": "80"},
+	}
+
+	for name, expected := range map[string]string{"rpc": "46657", "api": "80"} {
+		port, err := EndpointPort(srv, name)
+		if err != nil {
+			t.Fatalf("unexpected error resolving %q: %v", name, err)
+		}
+		if port != expected {
+			t.Fatalf("expected %q port %s, got %s", name, expected, port)
+		}
+	}
+
+	if _, err := EndpointPort(srv, "p2p"); err == nil {
+		t.Fatalf("expected an error resolving an undefined endpoint")
+	}
+
+	if address, _ := LinkedEndpoint("chain", srv, "rpc"); address != "chain:46657" {
+		t.Fatalf("expected chain:46657, got %s", address)
+	}
+}
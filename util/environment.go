@@ -0,0 +1,72 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ParseEnvFile reads the environment variables from a file in the
+// `docker run --env-file` format: one KEY=value pair per line, with
+// blank lines and lines starting with # ignored. A line with just a KEY
+// takes its value from the host environment (if it is set there).
+// ParseEnvFile returns file reading or format errors.
+func ParseEnvFile(fileName string) ([]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the env file: %v", err)
+	}
+	defer file.Close()
+
+	var (
+		env []string
+		n   int
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		n++
+
+		line := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		spl := strings.SplitN(line, "=", 2)
+		key := spl[0]
+		if key == "" || strings.IndexFunc(key, unicode.IsSpace) != -1 {
+			return nil, fmt.Errorf("Invalid variable name %q in the env file %s on line %d", key, fileName, n)
+		}
+
+		if len(spl) == 1 {
+			if value, ok := os.LookupEnv(key); ok {
+				line = key + "=" + value
+			}
+		}
+
+		env = append(env, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Cannot read the env file %s: %v", fileName, err)
+	}
+
+	return env, nil
+}
+
+// ParseEnvFiles reads the environment variables from the list of files
+// (see ParseEnvFile) and returns them in order.
+func ParseEnvFiles(fileNames []string) ([]string, error) {
+	var env []string
+
+	for _, fileName := range fileNames {
+		vars, err := ParseEnvFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, vars...)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,256 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	log "github.com/eris-ltd/eris-logger"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Health check defaults.
+const (
+	DefaultHealthInterval = time.Second
+	DefaultHealthTimeout  = time.Second
+	DefaultHealthRetries  = 30
+)
+
+// HealthCheckParams validates the health check definition and returns
+// its interval, timeout, and retries values with defaults filled in.
+func HealthCheckParams(hc *def.HealthCheck) (interval, timeout time.Duration, retries int, err error) {
+	interval, timeout, retries = DefaultHealthInterval, DefaultHealthTimeout, DefaultHealthRetries
+
+	if hc.Port == "" && hc.Exec == "" {
+		return 0, 0, 0, fmt.Errorf("Invalid health check: either port or exec should be given")
+	}
+	if hc.Port != "" && hc.Exec != "" {
+		return 0, 0, 0, fmt.Errorf("Invalid health check: port and exec cannot be used together")
+	}
+	if hc.HTTP != "" && hc.Port == "" {
+		return 0, 0, 0, fmt.Errorf("Invalid health check: http %q needs a port", hc.HTTP)
+	}
+
+	if hc.Interval != "" {
+		if interval, err = time.ParseDuration(hc.Interval); err != nil || interval <= 0 {
+			return 0, 0, 0, fmt.Errorf("Invalid health check interval %q: expecting a duration like 1s or 500ms", hc.Interval)
+		}
+	}
+	if hc.Timeout != "" {
+		if timeout, err = time.ParseDuration(hc.Timeout); err != nil || timeout <= 0 {
+			return 0, 0, 0, fmt.Errorf("Invalid health check timeout %q: expecting a duration like 1s or 500ms", hc.Timeout)
+		}
+	}
+	if hc.Retries < 0 {
+		return 0, 0, 0, fmt.Errorf("Invalid health check retries %d: expecting a positive number", hc.Retries)
+	} else if hc.Retries > 0 {
+		retries = hc.Retries
+	}
+
+	return interval, timeout, retries, nil
+}
+
+// HealthCheckLabel returns the health check definition
+// encoded to be stored in the container labels.
+func HealthCheckLabel(hc *def.HealthCheck) string {
+	label, err := json.Marshal(hc)
+	if err != nil {
+		return ""
+	}
+	return string(label)
+}
+
+// CheckHealth performs the health check for the container once
+// and returns nil if the container is healthy.
+func CheckHealth(container *docker.Container, hc *def.HealthCheck) error {
+	_, timeout, _, err := HealthCheckParams(hc)
+	if err != nil {
+		return err
+	}
+
+	if !container.State.Running {
+		return fmt.Errorf("Container %s is not running", container.Name)
+	}
+
+	if hc.Exec != "" {
+		return checkExec(container, hc.Exec, timeout)
+	}
+
+	address, err := ContainerAddress(container, hc.Port)
+	if err != nil {
+		return err
+	}
+
+	if hc.HTTP != "" {
+		return checkHTTP("http://"+address+"/"+strings.TrimPrefix(hc.HTTP, "/"), timeout)
+	}
+	return checkTCP(address, timeout)
+}
+
+// ContainerHealth returns the health state of the container (see
+// definitions.HealthNone and others) according to the health check
+// stored in the container labels during its creation. The check is
+// only performed if live is true, otherwise a running container is
+// reported as definitions.HealthUnknown.
+func ContainerHealth(container *docker.Container, live bool) string {
+	if container == nil || container.Config == nil {
+		return def.HealthNone
+	}
+
+	label := container.Config.Labels[def.LabelHealthCheck]
+	if label == "" {
+		return def.HealthNone
+	}
+
+	hc := new(def.HealthCheck)
+	if err := json.Unmarshal([]byte(label), hc); err != nil {
+		return def.HealthNone
+	}
+
+	if !container.State.Running {
+		return def.HealthUnhealthy
+	}
+
+	if !live {
+		return def.HealthUnknown
+	}
+
+	if err := CheckHealth(container, hc); err != nil {
+		log.WithField("=>", container.Name).Debugf("Health check failed: %v", err)
+
+		// Give the container some time to settle.
+		interval, _, retries, _ := HealthCheckParams(hc)
+		if time.Since(container.State.StartedAt) < interval*time.Duration(retries) {
+			return def.HealthStarting
+		}
+		return def.HealthUnhealthy
+	}
+	return def.HealthHealthy
+}
+
+// ContainerAddress returns the host:port address the container port
+// can be reached at: the published port on the Docker host if the port
+// is published, or the container IP address otherwise.
+func ContainerAddress(container *docker.Container, port string) (string, error) {
+	port = PortAndProtocol(port)
+
+	if container.NetworkSettings == nil {
+		return "", fmt.Errorf("Container %s has no network settings", container.Name)
+	}
+
+	for _, binding := range container.NetworkSettings.Ports[docker.Port(port)] {
+		if binding.HostPort == "" {
+			continue
+		}
+
+		host := binding.HostIP
+		if host == "" || host == "0.0.0.0" {
+			host = dockerHostIP()
+		}
+		return net.JoinHostPort(host, binding.HostPort), nil
+	}
+
+	if container.NetworkSettings.IPAddress == "" {
+		return "", fmt.Errorf("Port %s of container %s is not published and the container has no IP address", port, container.Name)
+	}
+	return net.JoinHostPort(container.NetworkSettings.IPAddress, strings.Split(port, "/")[0]), nil
+}
+
+// dockerHostIP returns the address of the Docker host taken
+// from the DOCKER_HOST variable, or the loopback address.
+func dockerHostIP() string {
+	if u, err := url.Parse(os.Getenv("DOCKER_HOST")); err == nil && u.Scheme == "tcp" {
+		if host, _, err := net.SplitHostPort(u.Host); err == nil {
+			return host
+		}
+	}
+	return "127.0.0.1"
+}
+
+func checkTCP(address string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func checkHTTP(address string, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(address)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("GET %s returned %s", address, resp.Status)
+	}
+	return nil
+}
+
+// execWatchdog runs the health check command ($1) in the background and
+// kills it if it is still running after the timeout ($2 seconds), so that
+// a slow check doesn't leave the command behind in the container.
+const execWatchdog = `sh -c "$1" & pid=$!
+(sleep "$2"; kill -9 $pid 2>/dev/null) & watchdog=$!
+wait $pid; status=$?
+kill $watchdog 2>/dev/null
+exit $status`
+
+func checkExec(container *docker.Container, command string, timeout time.Duration) error {
+	seconds := int(math.Ceil(timeout.Seconds()))
+	exec, err := DockerClient.CreateExec(docker.CreateExecOptions{
+		Container:    container.ID,
+		Cmd:          []string{"sh", "-c", execWatchdog, "sh", command, strconv.Itoa(seconds)},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := new(bytes.Buffer)
+	cw, err := DockerClient.StartExecNonBlocking(exec.ID, docker.StartExecOptions{
+		OutputStream: output,
+		ErrorStream:  output,
+	})
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cw.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-time.After(timeout):
+		// Detach from the exec; the watchdog kills the command itself.
+		cw.Close()
+		return fmt.Errorf("Command %q timed out after %v", command, timeout)
+	}
+
+	inspect, err := DockerClient.InspectExec(exec.ID)
+	if err != nil {
+		return err
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("Command %q exited with code %d: %s", command, inspect.ExitCode, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
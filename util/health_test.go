@@ -0,0 +1,88 @@
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var HealthCheckParamsTests = []struct {
+	in       def.HealthCheck
+	interval time.Duration
+	timeout  time.Duration
+	retries  int
+	err      bool
+}{
+	{def.HealthCheck{Port: "46657"}, DefaultHealthInterval, DefaultHealthTimeout, DefaultHealthRetries, false},
+	{def.HealthCheck{Exec: "true", Interval: "2s", Timeout: "500ms", Retries: 5}, 2 * time.Second, 500 * time.Millisecond, 5, false},
+	{def.HealthCheck{Port: "46657", HTTP: "/status"}, DefaultHealthInterval, DefaultHealthTimeout, DefaultHealthRetries, false},
+	{def.HealthCheck{}, 0, 0, 0, true},
+	{def.HealthCheck{Port: "46657", Exec: "true"}, 0, 0, 0, true},
+	{def.HealthCheck{HTTP: "/status"}, 0, 0, 0, true},
+	{def.HealthCheck{Port: "46657", Interval: "often"}, 0, 0, 0, true},
+	{def.HealthCheck{Port: "46657", Timeout: "-1s"}, 0, 0, 0, true},
+	{def.HealthCheck{Port: "46657", Retries: -1}, 0, 0, 0, true},
+}
+
+func TestHealthCheckParams(t *testing.T) {
+	for _, test := range HealthCheckParamsTests {
+		interval, timeout, retries, err := HealthCheckParams(&test.in)
+		if test.err {
+			if err == nil {
+				t.Fatalf("expected %+v to fail validation", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expected %+v to pass validation, got %v", test.in, err)
+		}
+		if interval != test.interval || timeout != test.timeout || retries != test.retries {
+			t.Fatalf("expected %v, %v, %v, got %v, %v, %v", test.interval, test.timeout, test.retries, interval, timeout, retries)
+		}
+	}
+}
+
+func TestCheckHealthHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	container := &docker.Container{
+		Name:  "test",
+		State: docker.State{Running: true},
+		NetworkSettings: &docker.NetworkSettings{
+			Ports: map[docker.Port][]docker.PortBinding{
+				"46657/tcp": {{HostIP: "127.0.0.1", HostPort: port}},
+			},
+		},
+	}
+
+	if err := CheckHealth(container, &def.HealthCheck{Port: "46657"}); err != nil {
+		t.Fatalf("expected TCP check to pass, got %v", err)
+	}
+	if err := CheckHealth(container, &def.HealthCheck{Port: "46657", HTTP: "status"}); err != nil {
+		t.Fatalf("expected HTTP check to pass, got %v", err)
+	}
+	if err := CheckHealth(container, &def.HealthCheck{Port: "46657", HTTP: "/missing"}); err == nil {
+		t.Fatalf("expected HTTP check to fail")
+	}
+	if err := CheckHealth(container, &def.HealthCheck{Port: "1234"}); err == nil {
+		t.Fatalf("expected check of an unpublished port to fail")
+	}
+
+	container.State.Running = false
+	if err := CheckHealth(container, &def.HealthCheck{Port: "46657"}); err == nil {
+		t.Fatalf("expected check of a stopped container to fail")
+	}
+}
@@ -20,43 +20,101 @@ func Merge(base, over interface{}) error {
 
 	baseFields := reflect.TypeOf(base).Elem().NumField()
 	for i := 0; i < baseFields; i++ {
-		a := reflect.ValueOf(base).Elem().Field(i)
-		b := reflect.ValueOf(over).Elem().Field(i)
+		mergeField(reflect.ValueOf(base).Elem().Field(i), reflect.ValueOf(over).Elem().Field(i))
+	}
+	return nil
+}
 
-		switch a.Kind() {
-		case reflect.Slice:
-			if b.IsNil() {
-				continue
-			}
+// DeepMerge is Merge applied recursively: fields which are structs or
+// pointers to structs are merged field by field rather than overwritten.
+// Base and over are pointers to structs of the same type. DeepMerge
+// returns ErrMergeParameters otherwise.
+func DeepMerge(base, over interface{}) error {
+	if err := checkStructsAreMergeable(base, over); err != nil {
+		return err
+	}
+	if reflect.TypeOf(base) != reflect.TypeOf(over) {
+		return ErrMergeParameters
+	}
 
-			if a.IsNil() {
-				a.Set(b)
-				continue
-			}
+	deepMerge(reflect.ValueOf(base).Elem(), reflect.ValueOf(over).Elem(), false)
+	return nil
+}
 
-			a.Set(reflect.AppendSlice(a, b))
-		case reflect.Map:
-			if b.IsNil() {
-				continue
-			}
+// DeepOverlay is DeepMerge, except that the non-empty slices of over
+// replace the base ones rather than being appended to them.
+func DeepOverlay(base, over interface{}) error {
+	if err := checkStructsAreMergeable(base, over); err != nil {
+		return err
+	}
+	if reflect.TypeOf(base) != reflect.TypeOf(over) {
+		return ErrMergeParameters
+	}
 
-			if a.IsNil() {
-				a.Set(b)
-				continue
-			}
+	deepMerge(reflect.ValueOf(base).Elem(), reflect.ValueOf(over).Elem(), true)
+	return nil
+}
+
+func deepMerge(a, b reflect.Value, replace bool) {
+	for i := 0; i < a.NumField(); i++ {
+		fa, fb := a.Field(i), b.Field(i)
+		if !fa.CanSet() {
+			continue
+		}
 
-			for _, key := range b.MapKeys() {
-				a.SetMapIndex(key, b.MapIndex(key))
+		switch {
+		case fa.Kind() == reflect.Struct:
+			deepMerge(fa, fb, replace)
+		case fa.Kind() == reflect.Ptr && fa.Type().Elem().Kind() == reflect.Struct:
+			if fb.IsNil() {
+				continue
 			}
-		default:
-			// Don't overwrite with zero values (0, "", false).
-			if b.Interface() == reflect.Zero(b.Type()).Interface() {
+			if fa.IsNil() {
+				fa.Set(fb)
 				continue
 			}
+			deepMerge(fa.Elem(), fb.Elem(), replace)
+		case replace && fa.Kind() == reflect.Slice && fb.Len() != 0:
+			fa.Set(fb)
+		default:
+			mergeField(fa, fb)
+		}
+	}
+}
+
+func mergeField(a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Slice:
+		if b.IsNil() {
+			return
+		}
+
+		if a.IsNil() {
 			a.Set(b)
+			return
+		}
+
+		a.Set(reflect.AppendSlice(a, b))
+	case reflect.Map:
+		if b.IsNil() {
+			return
 		}
+
+		if a.IsNil() {
+			a.Set(b)
+			return
+		}
+
+		for _, key := range b.MapKeys() {
+			a.SetMapIndex(key, b.MapIndex(key))
+		}
+	default:
+		// Don't overwrite with zero values (0, "", false).
+		if b.Interface() == reflect.Zero(b.Type()).Interface() {
+			return
+		}
+		a.Set(b)
 	}
-	return nil
 }
 
 func checkStructsAreMergeable(base, over interface{}) error {
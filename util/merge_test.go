@@ -192,3 +192,70 @@ func TestMergeError(t *testing.T) {
 		t.Fatalf("e11: expected error, got %v", err)
 	}
 }
+
+type N struct {
+	String string
+	Slice  []string
+	Inner  S
+	Ptr    *S
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := &N{
+		String: "a",
+		Inner:  S{String: "a", Map: map[string]string{"a": "1"}},
+		Ptr:    &S{Int: 1, Slice: []string{"1"}},
+	}
+	over := &N{
+		Slice: []string{"1"},
+		Inner: S{Map: map[string]string{"b": "2"}},
+		Ptr:   &S{String: "b", Slice: []string{"2"}},
+	}
+	want := &N{
+		String: "a",
+		Slice:  []string{"1"},
+		Inner:  S{String: "a", Map: map[string]string{"a": "1", "b": "2"}},
+		Ptr:    &S{String: "b", Int: 1, Slice: []string{"1", "2"}},
+	}
+
+	if err := DeepMerge(base, over); err != nil {
+		t.Fatalf("expected merge to succeed, got error %v", err)
+	}
+	if !reflect.DeepEqual(base, want) {
+		t.Fatalf("expected %v, got %v", want, base)
+	}
+
+	if err := DeepMerge(&N{}, &N{}); err != nil {
+		t.Fatalf("expected blank structs to merge, got %v", err)
+	}
+	if err := DeepMerge(&N{}, &S{}); err != ErrMergeParameters {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
+
+func TestDeepOverlay(t *testing.T) {
+	base := &N{
+		String: "a",
+		Slice:  []string{"1"},
+		Ptr:    &S{Int: 1, Slice: []string{"1"}, Map: map[string]string{"a": "1"}},
+	}
+	over := &N{
+		Ptr: &S{String: "b", Slice: []string{"2"}, Map: map[string]string{"b": "2"}},
+	}
+	want := &N{
+		String: "a",
+		Slice:  []string{"1"},
+		Ptr:    &S{String: "b", Int: 1, Slice: []string{"2"}, Map: map[string]string{"a": "1", "b": "2"}},
+	}
+
+	if err := DeepOverlay(base, over); err != nil {
+		t.Fatalf("expected overlay to succeed, got error %v", err)
+	}
+	if !reflect.DeepEqual(base, want) {
+		t.Fatalf("expected %v, got %v", want, base)
+	}
+
+	if err := DeepOverlay(&N{}, &S{}); err != ErrMergeParameters {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
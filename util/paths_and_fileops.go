@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/eris-ltd/eris-cli/config"
+
 	log "github.com/eris-ltd/eris-logger"
 
 	. "github.com/eris-ltd/common/go/common"
@@ -30,7 +32,7 @@ func GetFileByNameAndType(typ, name string) string {
 	files := GetGlobalLevelConfigFilesByType(typ, true)
 
 	for _, file := range files {
-		fileBase := strings.Split(filepath.Base(file), ".")[0] // quick and dirty file root
+		fileBase := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
 		if fileBase == name {
 			log.WithField("file", file).Debug("This file found")
 			return file
@@ -41,6 +43,11 @@ func GetFileByNameAndType(typ, name string) string {
 	return ""
 }
 
+// GetGlobalLevelConfigFilesByType returns the definition files of the typ
+// type found in the config.DefinitionPaths directories. A definition found
+// in a directory of higher precedence hides the same named definitions
+// of the rest. Environment overlay files (NAME.ENV.toml of a NAME
+// definition in the same directory) are not listed.
 // note this function fails silently.
 func GetGlobalLevelConfigFilesByType(typ string, withExt bool) []string {
 	var path string
@@ -51,28 +58,60 @@ func GetGlobalLevelConfigFilesByType(typ string, withExt bool) []string {
 		path = ChainsPath
 	case "actions":
 		path = ActionsPath
+	case "chain-types":
+		path = ChainTypePath
+	case "account-types":
+		path = AccountsTypePath
 	}
 
 	files := []string{}
-	fileTypes := []string{}
+	hidden := make(map[string]bool)
 
-	// TODO [csk]: DRY up how we deal with file extensions
-	for _, t := range []string{"*.json", "*.yaml", "*.toml"} {
-		fileTypes = append(fileTypes, filepath.Join(path, t))
-	}
+	for _, dir := range config.DefinitionPaths(path) {
+		found := make(map[string]bool)
+
+		// TODO [csk]: DRY up how we deal with file extensions
+		var matches []string
+		for _, t := range []string{"*.json", "*.yaml", "*.toml"} {
+			s, _ := filepath.Glob(filepath.Join(dir, t))
+			matches = append(matches, s...)
+		}
+		names := make(map[string]bool)
+		for _, s1 := range matches {
+			names[strings.TrimSuffix(filepath.Base(s1), filepath.Ext(s1))] = true
+		}
+
+		for _, s1 := range matches {
+			name := strings.TrimSuffix(filepath.Base(s1), filepath.Ext(s1))
+			if hidden[name] || isOverlayName(name, names) {
+				continue
+			}
+			found[name] = true
 
-	for _, t := range fileTypes {
-		s, _ := filepath.Glob(t)
-		for _, s1 := range s {
 			if !withExt {
-				s1 = strings.Split(filepath.Base(s1), ".")[0]
+				s1 = name
 			}
 			files = append(files, s1)
 		}
+
+		for name := range found {
+			hidden[name] = true
+		}
 	}
 	return files
 }
 
+// isOverlayName returns true if the name is NAME.ENV, with NAME
+// being one of the definitions found in the same directory.
+func isOverlayName(name string, names map[string]bool) bool {
+	for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+		if names[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
 func MoveOutOfDirAndRmDir(src, dst string) error {
 	log.WithFields(log.Fields{
 		"from": src,
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestIsOverlayName(t *testing.T) {
+	names := map[string]bool{
+		"keys":         true,
+		"keys.staging": true,
+		"my.svc":       true,
+		"my.svc.demo":  true,
+		"ipfs.local":   true,
+	}
+
+	for _, entry := range []struct {
+		name    string
+		overlay bool
+	}{
+		{"keys", false},
+		{"keys.staging", true},
+		{"my.svc", false},
+		{"my.svc.demo", true},
+		{"ipfs.local", false},
+	} {
+		if overlay := isOverlayName(entry.name, names); overlay != entry.overlay {
+			t.Fatalf("expected %q to be an overlay = %v, got %v", entry.name, entry.overlay, overlay)
+		}
+	}
+}
@@ -2,10 +2,20 @@ package util
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	log "github.com/eris-ltd/eris-logger"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
+// Time to wait for a connection when probing the Docker host ports.
+var portDialTimeout = 300 * time.Millisecond
+
 // PortAndProtol adds the protocol tag '/tcp' to the bare port number
 // if it's missing.
 func PortAndProtocol(port string) string {
@@ -106,3 +116,168 @@ func MapPorts(ports, assignments []string) map[string]string {
 
 	return m
 }
+
+// PortConflict describes a published port which is already taken
+// on the Docker host.
+type PortConflict struct {
+	// host port, e.g. "46657"
+	Published string
+	// container port, e.g. "46657/tcp"
+	Exposed string
+	// what holds the host port, e.g. "chain simplechain (eris_chain_simplechain_1)"
+	Owner string
+}
+
+func (c *PortConflict) Error() string {
+	return fmt.Sprintf("host port %s (for the container port %s) is already used by %s", c.Published, c.Exposed, c.Owner)
+}
+
+// CheckPorts looks up the published ports from the ports definition
+// (reassigned according to the assignments, see MapPorts) in the port
+// bindings of the running containers other than the self container, and
+// probes them on the Docker host for other listeners.
+//
+// If there are conflicts and auto is false, CheckPorts returns an error
+// naming the conflicting owners. If auto is true, the conflicting ports
+// are reassigned to free host ports. CheckPorts returns the resulting
+// assignments (one "published:exposed" element for every port in ports).
+func CheckPorts(ports, assignments []string, self string, auto bool) ([]string, error) {
+	owners := publishedPorts(self)
+	mapping := MapPorts(ports, assignments)
+
+	var (
+		conflicts []*PortConflict
+		result    []string
+		taken     = make(map[string]bool)
+	)
+	for _, published := range mapping {
+		taken[published] = true
+	}
+
+	for _, entry := range ports {
+		_, _, exposed := PortComponents(entry)
+		published := mapping[exposed]
+
+		if conflict := portConflict(owners, published, exposed); conflict != nil {
+			if !auto {
+				conflicts = append(conflicts, conflict)
+				continue
+			}
+
+			free, err := freePort(published, owners, taken)
+			if err != nil {
+				return nil, err
+			}
+			taken[free] = true
+
+			log.WithFields(log.Fields{
+				"=>":        self,
+				"port":      exposed,
+				"published": free,
+			}).Warnf("Host port %s is taken by %s. Publishing to a free port", published, conflict.Owner)
+			published = free
+		}
+
+		result = append(result, published+":"+exposed)
+	}
+
+	switch len(conflicts) {
+	case 0:
+		return result, nil
+	case 1:
+		return nil, fmt.Errorf("Cannot publish the ports of %s: %v. Please use the --ports or --auto-ports flags to publish to other ports", self, conflicts[0])
+	default:
+		var messages []string
+		for _, conflict := range conflicts {
+			messages = append(messages, "  "+conflict.Error())
+		}
+		return nil, fmt.Errorf("Cannot publish the ports of %s:\n%s\nPlease use the --ports or --auto-ports flags to publish to other ports", self, strings.Join(messages, "\n"))
+	}
+}
+
+// portConflict returns the conflict if the published port is taken
+// by a running container or a Docker host listener, or nil.
+func portConflict(owners map[string]string, published, exposed string) *PortConflict {
+	// Random or unpublished.
+	if published == "" || published == "0" {
+		return nil
+	}
+
+	protocol := strings.Split(exposed, "/")[1]
+	if owner, ok := owners[published+"/"+protocol]; ok {
+		return &PortConflict{Published: published, Exposed: exposed, Owner: owner}
+	}
+	if protocol == "tcp" && hostListening(published) {
+		return &PortConflict{Published: published, Exposed: exposed, Owner: "another process on the Docker host " + dockerHostIP()}
+	}
+	return nil
+}
+
+// publishedPorts returns the host ports ("46657/tcp") published by the running
+// containers, except the self container, mapped to their owner descriptions.
+func publishedPorts(self string) map[string]string {
+	owners := make(map[string]string)
+
+	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		return owners
+	}
+
+	for _, c := range containers {
+		name := strings.TrimLeft(c.Names[0], "/")
+		if name == self {
+			continue
+		}
+
+		owner := "container " + name
+		if shortName, ok := c.Labels[def.LabelShortName]; ok {
+			owner = fmt.Sprintf("%s %s (%s)", c.Labels[def.LabelType], shortName, name)
+		}
+
+		for _, port := range c.Ports {
+			if port.PublicPort == 0 {
+				continue
+			}
+			owners[fmt.Sprintf("%d/%s", port.PublicPort, port.Type)] = owner
+		}
+	}
+	return owners
+}
+
+// freePort returns the first host port after port which is neither taken
+// by the containers (owners), nor by other assignments, nor listened on.
+func freePort(port string, owners map[string]string, taken map[string]bool) (string, error) {
+	start, err := strconv.Atoi(port)
+	if err != nil {
+		return "", fmt.Errorf("Invalid host port %q", port)
+	}
+
+	for n := start + 1; n <= 65535; n++ {
+		candidate := strconv.Itoa(n)
+		if taken[candidate] {
+			continue
+		}
+		if _, ok := owners[candidate+"/tcp"]; ok {
+			continue
+		}
+		if _, ok := owners[candidate+"/udp"]; ok {
+			continue
+		}
+		if hostListening(candidate) {
+			continue
+		}
+		return candidate, nil
+	}
+	return "", fmt.Errorf("No free host port found after %s", port)
+}
+
+// hostListening returns true if something accepts TCP connections
+// at the port of the Docker host.
+func hostListening(port string) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(dockerHostIP(), port), portDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -1,7 +1,10 @@
 package util
 
 import (
+	"net"
+	"os"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -232,3 +235,42 @@ func TestMapPorts(t *testing.T) {
 		}
 	}
 }
+
+func TestPortConflict(t *testing.T) {
+	owners := map[string]string{"46657/tcp": "chain test (eris_chain_test_1)"}
+
+	if conflict := portConflict(owners, "46657", "46657/tcp"); conflict == nil || conflict.Owner != owners["46657/tcp"] {
+		t.Fatalf("expected a conflict with the chain, got %v", conflict)
+	}
+	if conflict := portConflict(owners, "46657", "46657/udp"); conflict != nil {
+		t.Fatalf("expected no conflict for another protocol, got %v", conflict)
+	}
+	if conflict := portConflict(owners, "", "46657/tcp"); conflict != nil {
+		t.Fatalf("expected no conflict for an unpublished port, got %v", conflict)
+	}
+
+	if os.Getenv("DOCKER_HOST") != "" {
+		t.Skip("Docker host is remote, skipping the host listener checks")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	if conflict := portConflict(owners, port, "80/tcp"); conflict == nil {
+		t.Fatalf("expected a conflict with the host listener on port %s", port)
+	}
+
+	n, _ := strconv.Atoi(port)
+	taken := map[string]bool{strconv.Itoa(n + 1): true}
+	free, err := freePort(port, owners, taken)
+	if err != nil {
+		t.Fatalf("unexpected error finding a free port: %v", err)
+	}
+	if free == port || taken[free] {
+		t.Fatalf("expected a free port after %s, got %s", port, free)
+	}
+}
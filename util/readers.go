@@ -1,7 +1,7 @@
 package util
 
 import (
-	"compress/gzip"
+	"archive/zip"
 	"fmt"
 	"io"
 	"os"
@@ -22,29 +22,76 @@ func UntarForDocker(reader io.Reader, name, dest string) error {
 	return archive.Untar(reader, dest, &archive.TarOptions{NoLchown: true}) //, Name: name})
 }
 
+// PackTarball writes the contents of the pathToTar directory into
+// the nameOfTar gzipped tarball and returns the tarball file name.
 func PackTarball(pathToTar, nameOfTar string) (string, error) {
-	reader, err := os.Open(pathToTar)
+	reader, err := TarForDocker(pathToTar, archive.Gzip)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
+	defer reader.Close()
 
-	fileName := filepath.Join(pathToTar, nameOfTar)
+	writer, err := os.Create(nameOfTar)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.Close()
+		return "", err
+	}
 
-	writer, err := os.Create(fileName)
+	return nameOfTar, writer.Close()
+}
+
+// PackZip writes the contents of the pathToZip directory into
+// the nameOfZip zip file and returns the zip file name.
+func PackZip(pathToZip, nameOfZip string) (string, error) {
+	file, err := os.Create(nameOfZip)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
-	defer writer.Close()
 
-	archiver := gzip.NewWriter(writer)
-	defer archiver.Close()
+	writer := zip.NewWriter(file)
+	err = filepath.Walk(pathToZip, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(pathToZip, name)
+		if err != nil {
+			return err
+		}
 
-	_, err = io.Copy(archiver, reader)
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(entry, src)
+		return err
+	})
 	if err != nil {
-		return "", nil
+		writer.Close()
+		file.Close()
+		return "", err
 	}
 
-	return fileName, nil
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return "", err
+	}
+	return nameOfZip, file.Close()
 }
 
 // give a tarballs' path